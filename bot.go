@@ -33,6 +33,12 @@ type BotAPI struct {
 	fileEndpoint    string
 	logger          any
 	loggingDisabled bool
+	retryPolicy     *RetryPolicy
+	rateLimiter     RateLimiter
+	validate        bool
+	uploadProgress  UploadProgressFunc
+
+	requestMiddleware []RequestMiddleware
 
 	stoppers []context.CancelFunc
 	mu       sync.RWMutex
@@ -85,6 +91,12 @@ func NewBotAPIWithOptions(token string, options ...BotAPIOption) (*BotAPI, error
 		fileEndpoint:    config.fileEndpoint,
 		logger:          config.logger,
 		loggingDisabled: config.loggingDisabled,
+		retryPolicy:     config.retryPolicy,
+		rateLimiter:     config.rateLimiter,
+		validate:        config.validate,
+		uploadProgress:  config.uploadProgress,
+
+		requestMiddleware: config.requestMiddleware,
 	}
 
 	self, err := bot.GetMe()
@@ -132,19 +144,58 @@ func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, er
 }
 
 func (bot *BotAPI) MakeRequestWithContext(ctx context.Context, endpoint string, params Params) (*APIResponse, error) {
-	return bot.executeRequest(ctx, endpoint, buildFormPayload(params), requestDebug{params: params})
+	return bot.executeRequest(ctx, endpoint, params, nil)
+}
+
+func (bot *BotAPI) executeRequest(ctx context.Context, endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
+	handler := chainRequestMiddleware(bot.sendRequest, bot.requestMiddleware...)
+
+	return handler(ctx, APIRequest{
+		Method: endpoint,
+		Params: params,
+		Files:  files,
+	})
+}
+
+func (bot *BotAPI) sendRequest(ctx context.Context, request APIRequest) (*APIResponse, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := bot.executeRequestOnce(ctx, request.Method, request.Params, request.Files)
+
+		delay, retry := bot.retryPolicy.retryDelay(ctx, err, attempt, request.Files)
+		if !retry {
+			return resp, err
+		}
+
+		bot.logRetry(ctx, request.Method, attempt, delay, err)
+		if waitErr := sleepContext(ctx, delay); waitErr != nil {
+			return resp, err
+		}
+	}
 }
 
-func (bot *BotAPI) executeRequest(ctx context.Context, endpoint string, payload requestPayload, debugInfo requestDebug) (*APIResponse, error) {
+func (bot *BotAPI) executeRequestOnce(ctx context.Context, endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
+	if bot.rateLimiter != nil {
+		if err := bot.rateLimiter.Wait(ctx, endpoint, params["chat_id"]); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := buildRequestPayload(params, files, bot.uploadProgressFunc(ctx, endpoint))
+	if err != nil {
+		return nil, err
+	}
 	defer payload.close()
 
-	bot.logRequestDebug(ctx, endpoint, debugInfo)
+	bot.logRequestDebug(ctx, endpoint, requestDebug{
+		params:    params,
+		fileCount: len(files),
+	})
 
 	method := fmt.Sprintf(bot.apiEndpoint, bot.Token, endpoint)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", method, payload.body)
 	if err != nil {
-		return &APIResponse{}, err
+		return &APIResponse{}, fmt.Errorf("%s: %w", endpoint, bot.redactToken(err))
 	}
 	if payload.contentType != "" {
 		req.Header.Set("Content-Type", payload.contentType)
@@ -152,14 +203,14 @@ func (bot *BotAPI) executeRequest(ctx context.Context, endpoint string, payload
 
 	resp, err := bot.Client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", endpoint, bot.redactToken(err))
 	}
 	defer resp.Body.Close()
 
 	var apiResp APIResponse
 	bytes, err := bot.decodeAPIResponse(resp.Body, &apiResp)
 	if err != nil {
-		return &apiResp, err
+		return &apiResp, fmt.Errorf("%s: decode response: %w", endpoint, err)
 	}
 
 	bot.logResponseDebug(ctx, endpoint, string(bytes))
@@ -211,15 +262,11 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 }
 
 func (bot *BotAPI) UploadFilesWithContext(ctx context.Context, endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
-	payload, err := buildMultipartPayload(params, files)
-	if err != nil {
-		return nil, err
+	if files == nil {
+		files = []RequestFile{}
 	}
 
-	return bot.executeRequest(ctx, endpoint, payload, requestDebug{
-		params:    params,
-		fileCount: len(files),
-	})
+	return bot.executeRequest(ctx, endpoint, params, files)
 }
 
 // GetFileDirectURL returns direct URL to file
@@ -255,7 +302,7 @@ func (bot *BotAPI) GetMeWithContext(ctx context.Context) (User, error) {
 	}
 
 	var user User
-	err = json.Unmarshal(resp.Result, &user)
+	err = decodeResult("getMe", resp.Result, &user)
 
 	return user, err
 }
@@ -273,6 +320,12 @@ func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
 }
 
 func (bot *BotAPI) RequestWithContext(ctx context.Context, c Chattable) (*APIResponse, error) {
+	if validator, ok := c.(Validator); ok && bot.validate {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	params, err := c.params()
 	if err != nil {
 		return nil, err
@@ -299,7 +352,7 @@ func (bot *BotAPI) Send(c Chattable) (Message, error) {
 	}
 
 	var message Message
-	err = json.Unmarshal(resp.Result, &message)
+	err = decodeResult(c.method(), resp.Result, &message)
 
 	return message, err
 }
@@ -311,7 +364,7 @@ func (bot *BotAPI) requestBool(c Chattable) (bool, error) {
 	}
 
 	var ok bool
-	err = json.Unmarshal(resp.Result, &ok)
+	err = decodeResult(c.method(), resp.Result, &ok)
 
 	return ok, err
 }
@@ -364,7 +417,7 @@ func (bot *BotAPI) SendMediaGroup(config MediaGroupConfig) ([]Message, error) {
 	}
 
 	var messages []Message
-	err = json.Unmarshal(resp.Result, &messages)
+	err = decodeResult(config.method(), resp.Result, &messages)
 
 	return messages, err
 }
@@ -377,7 +430,7 @@ func (bot *BotAPI) PostStory(config PostStoryConfig) (Story, error) {
 	}
 
 	var story Story
-	err = json.Unmarshal(resp.Result, &story)
+	err = decodeResult(config.method(), resp.Result, &story)
 
 	return story, err
 }
@@ -390,7 +443,7 @@ func (bot *BotAPI) EditStory(config EditStoryConfig) (Story, error) {
 	}
 
 	var story Story
-	err = json.Unmarshal(resp.Result, &story)
+	err = decodeResult(config.method(), resp.Result, &story)
 
 	return story, err
 }
@@ -403,7 +456,7 @@ func (bot *BotAPI) RepostStory(config RepostStoryConfig) (Story, error) {
 	}
 
 	var story Story
-	err = json.Unmarshal(resp.Result, &story)
+	err = decodeResult(config.method(), resp.Result, &story)
 
 	return story, err
 }
@@ -419,7 +472,7 @@ func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserPro
 	}
 
 	var profilePhotos UserProfilePhotos
-	err = json.Unmarshal(resp.Result, &profilePhotos)
+	err = decodeResult(config.method(), resp.Result, &profilePhotos)
 
 	return profilePhotos, err
 }
@@ -432,7 +485,7 @@ func (bot *BotAPI) GetUserProfileAudios(config UserProfileAudiosConfig) (UserPro
 	}
 
 	var profileAudios UserProfileAudios
-	err = json.Unmarshal(resp.Result, &profileAudios)
+	err = decodeResult(config.method(), resp.Result, &profileAudios)
 
 	return profileAudios, err
 }
@@ -445,7 +498,7 @@ func (bot *BotAPI) GetUserPersonalChatMessages(config UserPersonalChatMessagesCo
 	}
 
 	var messages []Message
-	err = json.Unmarshal(resp.Result, &messages)
+	err = decodeResult(config.method(), resp.Result, &messages)
 
 	return messages, err
 }
@@ -454,13 +507,17 @@ func (bot *BotAPI) GetUserPersonalChatMessages(config UserPersonalChatMessagesCo
 //
 // Requires FileID.
 func (bot *BotAPI) GetFile(config FileConfig) (File, error) {
-	resp, err := bot.Request(config)
+	return bot.GetFileWithContext(context.Background(), config)
+}
+
+func (bot *BotAPI) GetFileWithContext(ctx context.Context, config FileConfig) (File, error) {
+	resp, err := bot.RequestWithContext(ctx, config)
 	if err != nil {
 		return File{}, err
 	}
 
 	var file File
-	err = json.Unmarshal(resp.Result, &file)
+	err = decodeResult(config.method(), resp.Result, &file)
 
 	return file, err
 }
@@ -483,7 +540,7 @@ func (bot *BotAPI) GetUpdatesWithContext(ctx context.Context, config UpdateConfi
 	}
 
 	var updates []Update
-	err = json.Unmarshal(resp.Result, &updates)
+	err = decodeResult(config.method(), resp.Result, &updates)
 
 	return updates, err
 }
@@ -501,48 +558,26 @@ func (bot *BotAPI) GetWebhookInfoWithContext(ctx context.Context) (WebhookInfo,
 	}
 
 	var info WebhookInfo
-	err = json.Unmarshal(resp.Result, &info)
+	err = decodeResult("getWebhookInfo", resp.Result, &info)
 
 	return info, err
 }
 
 // GetUpdatesChan starts and returns a channel for getting updates.
+//
+// Use [BotAPI.StartPolling] to control the lifetime of the polling goroutine
+// with a context.
 func (bot *BotAPI) GetUpdatesChan(config UpdateConfig) UpdatesChannel {
-	ch := make(chan Update, bot.Buffer)
+	poller := bot.StartPolling(context.Background(), config, PollerConfig{
+		MinBackoff: time.Second * 3,
+		MaxBackoff: time.Second * 3,
+	})
 
-	ctx, cancel := context.WithCancel(context.Background())
 	bot.mu.Lock()
-	bot.stoppers = append(bot.stoppers, cancel)
+	bot.stoppers = append(bot.stoppers, poller.Stop)
 	bot.mu.Unlock()
 
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				close(ch)
-				return
-			default:
-			}
-
-			updates, err := bot.GetUpdatesWithContext(ctx, config)
-			if err != nil {
-				if ctx.Err() == nil {
-					bot.logUpdateError(ctx, err)
-					time.Sleep(time.Second * 3)
-				}
-				continue
-			}
-
-			for _, update := range updates {
-				if update.UpdateID >= config.Offset {
-					config.Offset = update.UpdateID + 1
-					ch <- update
-				}
-			}
-		}
-	}()
-
-	return ch
+	return poller.Updates()
 }
 
 // StopReceivingUpdates stops the go routine which receives updates
@@ -557,16 +592,16 @@ func (bot *BotAPI) StopReceivingUpdates() {
 }
 
 // ListenForWebhook registers a http handler for a webhook.
+//
+// The handler does not verify the secret token of requests. Use
+// [WebhookHandler] to verify requests before handling them.
 func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
 	ch := make(chan Update, bot.Buffer)
 
 	http.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
 		update, err := bot.HandleUpdate(r)
 		if err != nil {
-			errMsg, _ := json.Marshal(map[string]string{"error": err.Error()})
-			w.WriteHeader(http.StatusBadRequest)
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write(errMsg)
+			writeWebhookError(w, http.StatusBadRequest, err)
 			return
 		}
 
@@ -585,10 +620,7 @@ func (bot *BotAPI) ListenForWebhookRespReqFormat(w http.ResponseWriter, r *http.
 
 		update, err := bot.HandleUpdate(r)
 		if err != nil {
-			errMsg, _ := json.Marshal(map[string]string{"error": err.Error()})
-			w.WriteHeader(http.StatusBadRequest)
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write(errMsg)
+			writeWebhookError(w, http.StatusBadRequest, err)
 			return
 		}
 
@@ -600,6 +632,10 @@ func (bot *BotAPI) ListenForWebhookRespReqFormat(w http.ResponseWriter, r *http.
 
 // HandleUpdate parses and returns update received via webhook
 func (bot *BotAPI) HandleUpdate(r *http.Request) (*Update, error) {
+	return decodeWebhookUpdate(r)
+}
+
+func decodeWebhookUpdate(r *http.Request) (*Update, error) {
 	if r.Method != http.MethodPost {
 		err := errors.New("wrong HTTP method required POST")
 		return nil, err
@@ -614,6 +650,15 @@ func (bot *BotAPI) HandleUpdate(r *http.Request) (*Update, error) {
 	return &update, nil
 }
 
+func writeWebhookError(w http.ResponseWriter, status int, err error) {
+	errMsg, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(errMsg)
+}
+
+var errHTTPResponseUpload = errors.New("unable to use http response to upload files")
+
 // WriteToHTTPResponse writes the request to the HTTP ResponseWriter.
 //
 // It doesn't support uploading files.
@@ -629,7 +674,7 @@ func WriteToHTTPResponse(w http.ResponseWriter, c Chattable) error {
 	if t, ok := c.(Fileable); ok {
 		plan := uploadPlanFromFiles(t.files())
 		if plan.NeedsUpload() {
-			return errors.New("unable to use http response to upload files")
+			return errHTTPResponseUpload
 		}
 		params = plan.Apply(params)
 	}
@@ -650,7 +695,7 @@ func (bot *BotAPI) GetChat(config ChatInfoConfig) (ChatFullInfo, error) {
 	}
 
 	var chat ChatFullInfo
-	err = json.Unmarshal(resp.Result, &chat)
+	err = decodeResult(config.method(), resp.Result, &chat)
 
 	return chat, err
 }
@@ -666,7 +711,7 @@ func (bot *BotAPI) GetChatAdministrators(config ChatAdministratorsConfig) ([]Cha
 	}
 
 	var members []ChatMember
-	err = json.Unmarshal(resp.Result, &members)
+	err = decodeResult(config.method(), resp.Result, &members)
 
 	return members, err
 }
@@ -679,7 +724,7 @@ func (bot *BotAPI) GetChatMemberCount(config ChatMemberCountConfig) (int, error)
 	}
 
 	var count int
-	err = json.Unmarshal(resp.Result, &count)
+	err = decodeResult(config.method(), resp.Result, &count)
 
 	return count, err
 }
@@ -697,7 +742,7 @@ func (bot *BotAPI) GetChatMember(config GetChatMemberConfig) (ChatMember, error)
 	}
 
 	var member ChatMember
-	err = json.Unmarshal(resp.Result, &member)
+	err = decodeResult(config.method(), resp.Result, &member)
 
 	return member, err
 }
@@ -720,7 +765,7 @@ func (bot *BotAPI) GetGameHighScores(config GetGameHighScoresConfig) ([]GameHigh
 	}
 
 	var highScores []GameHighScore
-	err = json.Unmarshal(resp.Result, &highScores)
+	err = decodeResult(config.method(), resp.Result, &highScores)
 
 	return highScores, err
 }
@@ -733,7 +778,7 @@ func (bot *BotAPI) GetInviteLink(config ChatInviteLinkConfig) (string, error) {
 	}
 
 	var inviteLink string
-	err = json.Unmarshal(resp.Result, &inviteLink)
+	err = decodeResult(config.method(), resp.Result, &inviteLink)
 
 	return inviteLink, err
 }
@@ -746,7 +791,7 @@ func (bot *BotAPI) GetManagedBotToken(config GetManagedBotTokenConfig) (string,
 	}
 
 	var token string
-	err = json.Unmarshal(resp.Result, &token)
+	err = decodeResult(config.method(), resp.Result, &token)
 
 	return token, err
 }
@@ -759,7 +804,7 @@ func (bot *BotAPI) ReplaceManagedBotToken(config ReplaceManagedBotTokenConfig) (
 	}
 
 	var token string
-	err = json.Unmarshal(resp.Result, &token)
+	err = decodeResult(config.method(), resp.Result, &token)
 
 	return token, err
 }
@@ -772,7 +817,7 @@ func (bot *BotAPI) GetManagedBotAccessSettings(config GetManagedBotAccessSetting
 	}
 
 	var settings BotAccessSettings
-	err = json.Unmarshal(resp.Result, &settings)
+	err = decodeResult(config.method(), resp.Result, &settings)
 
 	return settings, err
 }
@@ -790,7 +835,7 @@ func (bot *BotAPI) GetMyStarBalance(config GetMyStarBalanceConfig) (StarAmount,
 	}
 
 	var balance StarAmount
-	err = json.Unmarshal(resp.Result, &balance)
+	err = decodeResult(config.method(), resp.Result, &balance)
 
 	return balance, err
 }
@@ -803,7 +848,7 @@ func (bot *BotAPI) GetBusinessAccountStarBalance(config GetBusinessAccountStarBa
 	}
 
 	var balance StarAmount
-	err = json.Unmarshal(resp.Result, &balance)
+	err = decodeResult(config.method(), resp.Result, &balance)
 
 	return balance, err
 }
@@ -816,7 +861,7 @@ func (bot *BotAPI) GetBusinessAccountGifts(config GetBusinessAccountGiftsConfig)
 	}
 
 	var gifts OwnedGifts
-	err = json.Unmarshal(resp.Result, &gifts)
+	err = decodeResult(config.method(), resp.Result, &gifts)
 
 	return gifts, err
 }
@@ -829,7 +874,7 @@ func (bot *BotAPI) GetUserGifts(config GetUserGiftsConfig) (OwnedGifts, error) {
 	}
 
 	var gifts OwnedGifts
-	err = json.Unmarshal(resp.Result, &gifts)
+	err = decodeResult(config.method(), resp.Result, &gifts)
 
 	return gifts, err
 }
@@ -842,7 +887,7 @@ func (bot *BotAPI) GetChatGifts(config GetChatGiftsConfig) (OwnedGifts, error) {
 	}
 
 	var gifts OwnedGifts
-	err = json.Unmarshal(resp.Result, &gifts)
+	err = decodeResult(config.method(), resp.Result, &gifts)
 
 	return gifts, err
 }
@@ -859,7 +904,7 @@ func (bot *BotAPI) CreateInvoiceLink(config InvoiceLinkConfig) (inviteLink strin
 		err = fmt.Errorf("returns error code: %d", resp.ErrorCode)
 		return
 	}
-	err = json.Unmarshal(resp.Result, &inviteLink)
+	err = decodeResult(config.method(), resp.Result, &inviteLink)
 
 	return
 }
@@ -872,7 +917,7 @@ func (bot *BotAPI) GetStickerSet(config GetStickerSetConfig) (StickerSet, error)
 	}
 
 	var stickerSet StickerSet
-	err = json.Unmarshal(resp.Result, &stickerSet)
+	err = decodeResult(config.method(), resp.Result, &stickerSet)
 
 	return stickerSet, err
 }
@@ -885,7 +930,7 @@ func (bot *BotAPI) GetCustomEmojiStickers(config GetCustomEmojiStickersConfig) (
 	}
 
 	var stickers []Sticker
-	err = json.Unmarshal(resp.Result, &stickers)
+	err = decodeResult(config.method(), resp.Result, &stickers)
 
 	return stickers, err
 }
@@ -898,7 +943,7 @@ func (bot *BotAPI) StopPoll(config StopPollConfig) (Poll, error) {
 	}
 
 	var poll Poll
-	err = json.Unmarshal(resp.Result, &poll)
+	err = decodeResult(config.method(), resp.Result, &poll)
 
 	return poll, err
 }
@@ -916,7 +961,7 @@ func (bot *BotAPI) GetMyCommandsWithConfig(config GetMyCommandsConfig) ([]BotCom
 	}
 
 	var commands []BotCommand
-	err = json.Unmarshal(resp.Result, &commands)
+	err = decodeResult(config.method(), resp.Result, &commands)
 
 	return commands, err
 }
@@ -931,7 +976,7 @@ func (bot *BotAPI) CopyMessage(config CopyMessageConfig) (MessageID, error) {
 	}
 
 	var messageID MessageID
-	err = json.Unmarshal(resp.Result, &messageID)
+	err = decodeResult(config.method(), resp.Result, &messageID)
 
 	return messageID, err
 }
@@ -946,7 +991,7 @@ func (bot *BotAPI) AnswerWebAppQuery(config AnswerWebAppQueryConfig) (SentWebApp
 		return sentWebAppMessage, err
 	}
 
-	err = json.Unmarshal(resp.Result, &sentWebAppMessage)
+	err = decodeResult(config.method(), resp.Result, &sentWebAppMessage)
 	return sentWebAppMessage, err
 }
 
@@ -959,7 +1004,7 @@ func (bot *BotAPI) AnswerGuestQuery(config AnswerGuestQueryConfig) (SentGuestMes
 		return sentGuestMessage, err
 	}
 
-	err = json.Unmarshal(resp.Result, &sentGuestMessage)
+	err = decodeResult(config.method(), resp.Result, &sentGuestMessage)
 	return sentGuestMessage, err
 }
 
@@ -982,7 +1027,7 @@ func (bot *BotAPI) GetMyDefaultAdministratorRights(config GetMyDefaultAdministra
 		return rights, err
 	}
 
-	err = json.Unmarshal(resp.Result, &rights)
+	err = decodeResult(config.method(), resp.Result, &rights)
 	return rights, err
 }
 
@@ -995,7 +1040,7 @@ func (bot *BotAPI) CreateForumTopic(config CreateForumTopicConfig) (ForumTopic,
 		return topic, err
 	}
 
-	err = json.Unmarshal(resp.Result, &topic)
+	err = decodeResult(config.method(), resp.Result, &topic)
 	return topic, err
 }
 
@@ -1007,7 +1052,7 @@ func SavePreparedInlineMessage[T InlineQueryResults](bot *BotAPI, config SavePre
 	}
 
 	var preparedInlineMessage PreparedInlineMessage
-	err = json.Unmarshal(resp.Result, &preparedInlineMessage)
+	err = decodeResult(config.method(), resp.Result, &preparedInlineMessage)
 
 	return preparedInlineMessage, err
 }
@@ -1020,7 +1065,7 @@ func (bot *BotAPI) SavePreparedKeyboardButton(config SavePreparedKeyboardButtonC
 	}
 
 	var preparedKeyboardButton PreparedKeyboardButton
-	err = json.Unmarshal(resp.Result, &preparedKeyboardButton)
+	err = decodeResult(config.method(), resp.Result, &preparedKeyboardButton)
 
 	return preparedKeyboardButton, err
 }
@@ -14,6 +14,12 @@ type botAPIConfig struct {
 	buffer          int
 	logger          any
 	loggingDisabled bool
+	retryPolicy     *RetryPolicy
+	rateLimiter     RateLimiter
+	validate        bool
+	uploadProgress  UploadProgressFunc
+
+	requestMiddleware []RequestMiddleware
 }
 
 // BotAPIOption configures a BotAPI instance created by NewBotAPIWithOptions.
@@ -0,0 +1,257 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+)
+
+// Callable is a Chattable whose result decodes into T.
+//
+// Every config in this package declares its result type, so [Call] returns
+// the result of any API method with the correct type.
+type Callable[T any] interface {
+	Chattable
+	result(T)
+}
+
+// Call makes the request for c and decodes its result.
+//
+// Methods editing messages return True instead of the message when an inline
+// message is edited. Call returns the zero value of T in that case.
+func Call[T any](ctx context.Context, bot *BotAPI, c Callable[T]) (T, error) {
+	var result T
+
+	resp, err := bot.RequestWithContext(ctx, c)
+	if err != nil {
+		return result, err
+	}
+
+	if _, isBool := any(result).(bool); !isBool && bytes.Equal(resp.Result, []byte("true")) {
+		return result, nil
+	}
+
+	err = decodeResult(c.method(), resp.Result, &result)
+
+	return result, err
+}
+
+func (MessageConfig) result(Message)                 {}
+func (SendChecklistConfig) result(Message)           {}
+func (SendRichMessageConfig) result(Message)         {}
+func (ForwardConfig) result(Message)                 {}
+func (PhotoConfig) result(Message)                   {}
+func (SendLivePhotoConfig) result(Message)           {}
+func (AudioConfig) result(Message)                   {}
+func (DocumentConfig) result(Message)                {}
+func (StickerConfig) result(Message)                 {}
+func (VideoConfig) result(Message)                   {}
+func (AnimationConfig) result(Message)               {}
+func (VideoNoteConfig) result(Message)               {}
+func (PaidMediaConfig) result(Message)               {}
+func (VoiceConfig) result(Message)                   {}
+func (LocationConfig) result(Message)                {}
+func (VenueConfig) result(Message)                   {}
+func (ContactConfig) result(Message)                 {}
+func (SendPollConfig) result(Message)                {}
+func (GameConfig) result(Message)                    {}
+func (DiceConfig) result(Message)                    {}
+func (InvoiceConfig) result(Message)                 {}
+func (EditMessageLiveLocationConfig) result(Message) {}
+func (StopMessageLiveLocationConfig) result(Message) {}
+func (SetGameScoreConfig) result(Message)            {}
+func (EditMessageTextConfig) result(Message)         {}
+func (EditMessageCaptionConfig) result(Message)      {}
+func (EditMessageMediaConfig) result(Message)        {}
+func (EditMessageReplyMarkupConfig) result(Message)  {}
+func (EditMessageChecklistConfig) result(Message)    {}
+
+func (MediaGroupConfig) result([]Message)               {}
+func (UserPersonalChatMessagesConfig) result([]Message) {}
+
+func (CopyMessageConfig) result(MessageID) {}
+
+func (ForwardMessagesConfig) result([]MessageID) {}
+func (CopyMessagesConfig) result([]MessageID)    {}
+
+func (StopPollConfig) result(Poll) {}
+
+func (GetGameHighScoresConfig) result([]GameHighScore) {}
+
+func (LogOutConfig) result(bool)                            {}
+func (CloseConfig) result(bool)                             {}
+func (SendMessageDraftConfig) result(bool)                  {}
+func (SendRichMessageDraftConfig) result(bool)              {}
+func (ChatActionConfig) result(bool)                        {}
+func (EditEphemeralMessageTextConfig) result(bool)          {}
+func (EditEphemeralMessageMediaConfig) result(bool)         {}
+func (EditEphemeralMessageCaptionConfig) result(bool)       {}
+func (EditEphemeralMessageReplyMarkupConfig) result(bool)   {}
+func (DeleteEphemeralMessageConfig) result(bool)            {}
+func (ApproveSuggestedPostConfig) result(bool)              {}
+func (DeclineSuggestedPostConfig) result(bool)              {}
+func (SetMessageReactionConfig) result(bool)                {}
+func (DeleteMessageReactionConfig) result(bool)             {}
+func (DeleteAllMessageReactionsConfig) result(bool)         {}
+func (SetUserEmojiStatusConfig) result(bool)                {}
+func (WebhookConfig) result(bool)                           {}
+func (DeleteWebhookConfig) result(bool)                     {}
+func (InlineConfig) result(bool)                            {}
+func (AnswerChatJoinRequestQueryConfig) result(bool)        {}
+func (SendChatJoinRequestWebAppConfig) result(bool)         {}
+func (CallbackConfig) result(bool)                          {}
+func (UnbanChatMemberConfig) result(bool)                   {}
+func (BanChatMemberConfig) result(bool)                     {}
+func (RestrictChatMemberConfig) result(bool)                {}
+func (PromoteChatMemberConfig) result(bool)                 {}
+func (SetChatAdministratorCustomTitle) result(bool)         {}
+func (SetChatMemberTagConfig) result(bool)                  {}
+func (BanChatSenderChatConfig) result(bool)                 {}
+func (UnbanChatSenderChatConfig) result(bool)               {}
+func (SetChatPermissionsConfig) result(bool)                {}
+func (ApproveChatJoinRequestConfig) result(bool)            {}
+func (DeclineChatJoinRequest) result(bool)                  {}
+func (LeaveChatConfig) result(bool)                         {}
+func (ShippingConfig) result(bool)                          {}
+func (PreCheckoutConfig) result(bool)                       {}
+func (SetPassportDataErrorsConfig) result(bool)             {}
+func (TransferBusinessAccountStarsConfig) result(bool)      {}
+func (RefundStarPaymentConfig) result(bool)                 {}
+func (EditUserStarSubscriptionConfig) result(bool)          {}
+func (DeleteMessageConfig) result(bool)                     {}
+func (DeleteMessagesConfig) result(bool)                    {}
+func (SendGiftConfig) result(bool)                          {}
+func (GiftPremiumSubscriptionConfig) result(bool)           {}
+func (ConvertGiftToStarsConfig) result(bool)                {}
+func (UpgradeGiftConfig) result(bool)                       {}
+func (TransferGiftConfig) result(bool)                      {}
+func (VerifyUserConfig) result(bool)                        {}
+func (VerifyChatConfig) result(bool)                        {}
+func (RemoveUserVerificationConfig) result(bool)            {}
+func (RemoveChatVerificationConfig) result(bool)            {}
+func (PinChatMessageConfig) result(bool)                    {}
+func (UnpinChatMessageConfig) result(bool)                  {}
+func (UnpinAllChatMessagesConfig) result(bool)              {}
+func (SetChatPhotoConfig) result(bool)                      {}
+func (DeleteChatPhotoConfig) result(bool)                   {}
+func (SetChatTitleConfig) result(bool)                      {}
+func (SetChatDescriptionConfig) result(bool)                {}
+func (NewStickerSetConfig) result(bool)                     {}
+func (AddStickerConfig) result(bool)                        {}
+func (SetStickerPositionConfig) result(bool)                {}
+func (SetCustomEmojiStickerSetThumbnailConfig) result(bool) {}
+func (SetStickerSetTitleConfig) result(bool)                {}
+func (DeleteStickerSetConfig) result(bool)                  {}
+func (DeleteStickerConfig) result(bool)                     {}
+func (ReplaceStickerInSetConfig) result(bool)               {}
+func (SetStickerEmojiListConfig) result(bool)               {}
+func (SetStickerKeywordsConfig) result(bool)                {}
+func (SetStickerMaskPositionConfig) result(bool)            {}
+func (SetStickerSetThumbConfig) result(bool)                {}
+func (SetChatStickerSetConfig) result(bool)                 {}
+func (DeleteChatStickerSetConfig) result(bool)              {}
+func (EditForumTopicConfig) result(bool)                    {}
+func (CloseForumTopicConfig) result(bool)                   {}
+func (ReopenForumTopicConfig) result(bool)                  {}
+func (DeleteForumTopicConfig) result(bool)                  {}
+func (UnpinAllForumTopicMessagesConfig) result(bool)        {}
+func (EditGeneralForumTopicConfig) result(bool)             {}
+func (CloseGeneralForumTopicConfig) result(bool)            {}
+func (ReopenGeneralForumTopicConfig) result(bool)           {}
+func (HideGeneralForumTopicConfig) result(bool)             {}
+func (UnhideGeneralForumTopicConfig) result(bool)           {}
+func (UnpinAllGeneralForumTopicMessagesConfig) result(bool) {}
+func (SetManagedBotAccessSettingsConfig) result(bool)       {}
+func (ReadBusinessMessageConfig) result(bool)               {}
+func (DeleteBusinessMessagesConfig) result(bool)            {}
+func (SetBusinessAccountNameConfig) result(bool)            {}
+func (SetBusinessAccountUsernameConfig) result(bool)        {}
+func (SetBusinessAccountBioConfig) result(bool)             {}
+func (SetBusinessAccountGiftSettingsConfig) result(bool)    {}
+func (SetBusinessAccountProfilePhotoConfig) result(bool)    {}
+func (RemoveBusinessAccountProfilePhotoConfig) result(bool) {}
+func (DeleteStoryConfig) result(bool)                       {}
+func (SetMyCommandsConfig) result(bool)                     {}
+func (DeleteMyCommandsConfig) result(bool)                  {}
+func (SetMyNameConfig) result(bool)                         {}
+func (SetMyProfilePhotoConfig) result(bool)                 {}
+func (RemoveMyProfilePhotoConfig) result(bool)              {}
+func (SetMyDescriptionConfig) result(bool)                  {}
+func (SetMyShortDescriptionConfig) result(bool)             {}
+func (SetChatMenuButtonConfig) result(bool)                 {}
+func (SetMyDefaultAdministratorRightsConfig) result(bool)   {}
+
+func (ChatMemberCountConfig) result(int) {}
+
+func (ChatInviteLinkConfig) result(string)         {}
+func (InvoiceLinkConfig) result(string)            {}
+func (GetManagedBotTokenConfig) result(string)     {}
+func (ReplaceManagedBotTokenConfig) result(string) {}
+
+func (FileConfig) result(File)          {}
+func (UploadStickerConfig) result(File) {}
+
+func (UpdateConfig) result([]Update) {}
+
+func (UserProfilePhotosConfig) result(UserProfilePhotos) {}
+
+func (UserProfileAudiosConfig) result(UserProfileAudios) {}
+
+func (ChatInfoConfig) result(ChatFullInfo) {}
+
+func (GetChatMemberConfig) result(ChatMember) {}
+
+func (ChatAdministratorsConfig) result([]ChatMember) {}
+
+func (CreateChatInviteLinkConfig) result(ChatInviteLink)       {}
+func (EditChatInviteLinkConfig) result(ChatInviteLink)         {}
+func (CreateChatSubscriptionLinkConfig) result(ChatInviteLink) {}
+func (EditChatSubscriptionLinkConfig) result(ChatInviteLink)   {}
+func (RevokeChatInviteLinkConfig) result(ChatInviteLink)       {}
+
+func (AnswerWebAppQueryConfig) result(SentWebAppMessage) {}
+
+func (AnswerGuestQueryConfig) result(SentGuestMessage) {}
+
+func (SavePreparedKeyboardButtonConfig) result(PreparedKeyboardButton) {}
+
+func (GetStarTransactionsConfig) result(StarTransactions) {}
+
+func (GetMyStarBalanceConfig) result(StarAmount)              {}
+func (GetBusinessAccountStarBalanceConfig) result(StarAmount) {}
+
+func (GetAvailableGiftsConfig) result(Gifts) {}
+
+func (GetUserGiftsConfig) result(OwnedGifts)            {}
+func (GetChatGiftsConfig) result(OwnedGifts)            {}
+func (GetBusinessAccountGiftsConfig) result(OwnedGifts) {}
+
+func (GetStickerSetConfig) result(StickerSet) {}
+
+func (GetCustomEmojiStickersConfig) result([]Sticker)    {}
+func (GetForumTopicIconStickersConfig) result([]Sticker) {}
+
+func (CreateForumTopicConfig) result(ForumTopic) {}
+
+func (GetUserChatBoostsConfig) result(UserChatBoosts) {}
+
+func (GetManagedBotAccessSettingsConfig) result(BotAccessSettings) {}
+
+func (GetBusinessConnectionConfig) result(BusinessConnection) {}
+
+func (PostStoryConfig) result(Story)   {}
+func (EditStoryConfig) result(Story)   {}
+func (RepostStoryConfig) result(Story) {}
+
+func (GetMyCommandsConfig) result([]BotCommand) {}
+
+func (GetMyNameConfig) result(BotName) {}
+
+func (GetMyDescriptionConfig) result(BotDescription) {}
+
+func (GetMyShortDescriptionConfig) result(BotShortDescription) {}
+
+func (GetChatMenuButtonConfig) result(MenuButton) {}
+
+func (GetMyDefaultAdministratorRightsConfig) result(ChatAdministratorRights) {}
+
+func (SavePreparedInlineMessageConfig[T]) result(PreparedInlineMessage) {}
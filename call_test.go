@@ -0,0 +1,87 @@
+package tgbotapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func resultResponse(result string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true,"result":` + result + `}`)),
+	}
+}
+
+func TestCallDecodesResultType(t *testing.T) {
+	var method string
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			method = req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+			switch method {
+			case "copyMessages":
+				return resultResponse(`[{"message_id":1},{"message_id":2}]`), nil
+			case "createChatInviteLink":
+				return resultResponse(`{"invite_link":"https://t.me/+abc","creates_join_request":true}`), nil
+			case "getChatMenuButton":
+				return resultResponse(`{"type":"commands"}`), nil
+			default:
+				return resultResponse(`true`), nil
+			}
+		},
+	})
+	ctx := context.Background()
+
+	ids, err := Call(ctx, bot, CopyMessagesConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[1].MessageID != 2 {
+		t.Fatalf("unexpected message IDs %#v", ids)
+	}
+
+	link, err := Call(ctx, bot, CreateChatInviteLinkConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.InviteLink != "https://t.me/+abc" || !link.CreatesJoinRequest {
+		t.Fatalf("unexpected invite link %#v", link)
+	}
+
+	button, err := Call(ctx, bot, GetChatMenuButtonConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if button.Type != "commands" {
+		t.Fatalf("unexpected menu button %#v", button)
+	}
+
+	ok, err := Call(ctx, bot, NewChatAction(1, ChatTyping))
+	if err != nil || !ok {
+		t.Fatalf("unexpected chat action result %v, %v", ok, err)
+	}
+	if method != "sendChatAction" {
+		t.Fatalf("unexpected method %s", method)
+	}
+}
+
+func TestCallInlineEditReturnsZeroValue(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			return resultResponse(`true`), nil
+		},
+	})
+
+	message, err := Call(context.Background(), bot, EditMessageTextConfig{
+		BaseEdit: BaseEdit{InlineMessageID: "inline"},
+		Text:     "text",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 0 {
+		t.Fatalf("expected zero message, got %#v", message)
+	}
+}
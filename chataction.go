@@ -0,0 +1,56 @@
+package tgbotapi
+
+import (
+	"context"
+	"time"
+)
+
+// chatActionInterval is how often chat actions are sent again. Telegram shows
+// a chat action for 5 seconds or until the bot sends a message.
+const chatActionInterval = 4 * time.Second
+
+// StartChatAction shows the chat action of config, such as ChatTyping, until
+// ctx is done or the returned function is called. The chat action is sent
+// right away and again every few seconds, so it keeps showing during long
+// operations. Errors sending it are ignored.
+//
+//	stop := bot.StartChatAction(ctx, tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping))
+//	defer stop()
+//
+// The returned function waits for a chat action being sent to finish and may
+// be called more than once.
+func (bot *BotAPI) StartChatAction(ctx context.Context, config ChatActionConfig) (stop func()) {
+	return keepChatAction(ctx, chatActionInterval, func(ctx context.Context) {
+		_, _ = bot.RequestWithContext(ctx, config)
+	})
+}
+
+// keepChatAction calls send right away and then every interval until ctx is
+// done or the returned function is called. The returned function waits for
+// the last call of send to return.
+func keepChatAction(ctx context.Context, interval time.Duration, send func(context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			send(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
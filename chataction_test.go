@@ -0,0 +1,63 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartChatAction(t *testing.T) {
+	requests := make(chan string, 10)
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseForm(); err != nil {
+				return nil, err
+			}
+			requests <- req.URL.Path + "?" + req.PostForm.Encode()
+			return nil, errors.New("network down")
+		},
+	})
+
+	config := NewChatAction(1, ChatTyping)
+	config.MessageThreadID = 2
+	stop := bot.StartChatAction(context.Background(), config)
+
+	select {
+	case request := <-requests:
+		if want := "/bottoken/sendChatAction?action=typing&chat_id=1&message_thread_id=2"; request != want {
+			t.Fatalf("unexpected request %q, want %q", request, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("chat action wasn't sent")
+	}
+
+	stop()
+	stop()
+}
+
+func TestKeepChatAction(t *testing.T) {
+	var sent atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := keepChatAction(ctx, time.Millisecond, func(context.Context) {
+		sent.Add(1)
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for sent.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	stop()
+
+	count := sent.Load()
+	if count < 3 {
+		t.Fatalf("expected the chat action to be refreshed, sent %d times", count)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if sent.Load() != count {
+		t.Fatal("chat action sent after stop")
+	}
+}
@@ -0,0 +1,184 @@
+package tgbotapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// UpdateHandlerFunc handles a single update.
+type UpdateHandlerFunc func(ctx context.Context, update Update) error
+
+// UpdatePredicate reports whether an update should be handled by a route.
+type UpdatePredicate func(update Update) bool
+
+// Dispatcher routes updates to handlers.
+//
+// Routes are checked in the order they were registered and only the first
+// matching route handles an update. Updates matching no route are passed to
+// the fallback handler, if one is set.
+//
+// A Dispatcher is safe for concurrent use.
+type Dispatcher struct {
+	mu           sync.RWMutex
+	routes       []dispatcherRoute
+	fallback     UpdateHandlerFunc
+	middlewares  []UpdateMiddleware
+	errorHandler func(ctx context.Context, update Update, err error)
+}
+
+type dispatcherRoute struct {
+	match   UpdatePredicate
+	handler UpdateHandlerFunc
+}
+
+// NewDispatcher creates an empty Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// Handle registers a handler for updates matching predicate.
+func (d *Dispatcher) Handle(predicate UpdatePredicate, handler UpdateHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.routes = append(d.routes, dispatcherRoute{
+		match:   predicate,
+		handler: handler,
+	})
+}
+
+// HandleUpdateType registers a handler for updates of a kind, one of the
+// UpdateType constants.
+func (d *Dispatcher) HandleUpdateType(updateType string, handler UpdateHandlerFunc) {
+	d.Handle(func(update Update) bool {
+		return update.Type() == updateType
+	}, handler)
+}
+
+// HandleCommand registers a handler for messages with the given command.
+//
+// The command is matched without the leading slash and the at name syntax,
+// so "start" matches both "/start" and "/start@my_bot".
+func (d *Dispatcher) HandleCommand(command string, handler UpdateHandlerFunc) {
+	command = strings.TrimPrefix(command, "/")
+
+	d.Handle(func(update Update) bool {
+		return update.Message != nil && update.Message.Command() == command
+	}, handler)
+}
+
+// HandleCallbackPrefix registers a handler for callback queries whose data
+// starts with prefix.
+func (d *Dispatcher) HandleCallbackPrefix(prefix string, handler UpdateHandlerFunc) {
+	d.Handle(func(update Update) bool {
+		return update.CallbackQuery != nil && strings.HasPrefix(update.CallbackQuery.Data, prefix)
+	}, handler)
+}
+
+// HandleFallback sets the handler for updates matching no route.
+func (d *Dispatcher) HandleFallback(handler UpdateHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.fallback = handler
+}
+
+// Use adds middleware wrapping every handler of the dispatcher, including the
+// fallback. The first middleware is the outermost one.
+func (d *Dispatcher) Use(middlewares ...UpdateMiddleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
+// OnError sets a function called with errors returned by handlers while
+// running [Dispatcher.Run].
+func (d *Dispatcher) OnError(handler func(ctx context.Context, update Update, err error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.errorHandler = handler
+}
+
+// Dispatch passes the update to the first matching handler and returns its
+// error. Updates matching no route and no fallback are ignored.
+func (d *Dispatcher) Dispatch(ctx context.Context, update Update) error {
+	handler := d.handlerFor(update)
+	if handler == nil {
+		return nil
+	}
+
+	return handler(ctx, update)
+}
+
+func (d *Dispatcher) handlerFor(update Update) UpdateHandlerFunc {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	handler := d.fallback
+	for _, route := range d.routes {
+		if route.match(update) {
+			handler = route.handler
+			break
+		}
+	}
+	if handler == nil {
+		return nil
+	}
+
+	return ChainUpdateMiddleware(handler, d.middlewares...)
+}
+
+// Run dispatches updates from the channel one at a time until the channel is
+// closed or ctx is done.
+//
+// It works with channels returned by both [BotAPI.GetUpdatesChan] and
+// [BotAPI.ListenForWebhook].
+func (d *Dispatcher) Run(ctx context.Context, updates UpdatesChannel) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := d.Dispatch(ctx, update); err != nil {
+				d.handleError(ctx, update, err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) handleError(ctx context.Context, update Update, err error) {
+	d.mu.RLock()
+	handler := d.errorHandler
+	d.mu.RUnlock()
+
+	if handler != nil {
+		handler(ctx, update, err)
+	}
+}
+
+// ServeHTTP dispatches an update received via webhook.
+//
+// The update is handled before the response is written. Handler errors are
+// reported to Telegram with a server error so the update is delivered again.
+func (d *Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	update, err := decodeWebhookUpdate(r)
+	if err != nil {
+		writeWebhookError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := d.Dispatch(r.Context(), *update); err != nil {
+		d.handleError(r.Context(), *update, err)
+		writeWebhookError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,123 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func commandUpdate(text string) Update {
+	command := text
+	if i := strings.Index(text, " "); i != -1 {
+		command = text[:i]
+	}
+	return Update{Message: &Message{
+		Text:     text,
+		Entities: []MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+	}}
+}
+
+func TestDispatcherRoutesInRegistrationOrder(t *testing.T) {
+	var handled []string
+	record := func(name string) UpdateHandlerFunc {
+		return func(ctx context.Context, update Update) error {
+			handled = append(handled, name)
+			return nil
+		}
+	}
+
+	dispatcher := NewDispatcher()
+	dispatcher.HandleCommand("/start", record("start"))
+	dispatcher.HandleCallbackPrefix("vote:", record("vote"))
+	dispatcher.HandleUpdateType(UpdateTypeMessage, record("message"))
+	dispatcher.Handle(func(update Update) bool { return update.Message != nil }, record("unreachable"))
+	dispatcher.HandleFallback(record("fallback"))
+
+	updates := []Update{
+		commandUpdate("/start@test_bot now"),
+		{CallbackQuery: &CallbackQuery{Data: "vote:yes"}},
+		{CallbackQuery: &CallbackQuery{Data: "other"}},
+		{Message: &Message{Text: "hello"}},
+		commandUpdate("/help"),
+		{InlineQuery: &InlineQuery{}},
+	}
+	for _, update := range updates {
+		if err := dispatcher.Dispatch(context.Background(), update); err != nil {
+			t.Fatalf("dispatch failed: %v", err)
+		}
+	}
+
+	want := []string{"start", "vote", "fallback", "message", "message", "fallback"}
+	if strings.Join(handled, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected routing %v, want %v", handled, want)
+	}
+}
+
+func TestDispatcherRunReportsErrors(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+	dispatcher := NewDispatcher()
+	dispatcher.HandleFallback(func(ctx context.Context, update Update) error {
+		if update.UpdateID == 2 {
+			return handlerErr
+		}
+		return nil
+	})
+
+	var failed []int
+	dispatcher.OnError(func(ctx context.Context, update Update, err error) {
+		if !errors.Is(err, handlerErr) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		failed = append(failed, update.UpdateID)
+	})
+
+	ch := make(chan Update, 3)
+	ch <- Update{UpdateID: 1}
+	ch <- Update{UpdateID: 2}
+	ch <- Update{UpdateID: 3}
+	close(ch)
+
+	if err := dispatcher.Run(context.Background(), ch); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0] != 2 {
+		t.Fatalf("unexpected failed updates: %v", failed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dispatcher.Run(ctx, make(chan Update)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled run, got %v", err)
+	}
+}
+
+func TestDispatcherServeHTTP(t *testing.T) {
+	dispatcher := NewDispatcher()
+	dispatcher.HandleCallbackPrefix("fail", func(ctx context.Context, update Update) error {
+		return errors.New("handler failed")
+	})
+
+	tests := []struct {
+		method string
+		body   string
+		status int
+	}{
+		{http.MethodPost, `{"update_id":1,"callback_query":{"id":"1","data":"ok"}}`, http.StatusOK},
+		{http.MethodPost, `{"update_id":2,"callback_query":{"id":"2","data":"fail"}}`, http.StatusInternalServerError},
+		{http.MethodPost, `{`, http.StatusBadRequest},
+		{http.MethodGet, ``, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(test.method, "/webhook", strings.NewReader(test.body))
+		dispatcher.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Fatalf("%s %s: expected status %d, got %d", test.method, test.body, test.status, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrFileTooLarge is returned when a downloaded file exceeds
+// DownloadConfig.MaxSize.
+var ErrFileTooLarge = errors.New("file exceeds maximum download size")
+
+// DownloadConfig configures a file download.
+type DownloadConfig struct {
+	// MaxSize limits the size of the downloaded file in bytes.
+	// Zero means no limit.
+	MaxSize int64
+}
+
+// DownloadFile gets the file with the given ID and writes its content to w.
+//
+// Files stored by a local Bot API server are read from the local file system.
+func (bot *BotAPI) DownloadFile(fileID string, w io.Writer, config DownloadConfig) (File, error) {
+	return bot.DownloadFileWithContext(context.Background(), fileID, w, config)
+}
+
+func (bot *BotAPI) DownloadFileWithContext(ctx context.Context, fileID string, w io.Writer, config DownloadConfig) (File, error) {
+	file, err := bot.GetFileWithContext(ctx, FileConfig{FileID: fileID})
+	if err != nil {
+		return file, err
+	}
+
+	_, err = bot.DownloadFileContentWithContext(ctx, file, w, config)
+	return file, err
+}
+
+// DownloadFileContent writes the content of a file returned by
+// [BotAPI.GetFile] to w and returns the number of written bytes.
+func (bot *BotAPI) DownloadFileContent(file File, w io.Writer, config DownloadConfig) (int64, error) {
+	return bot.DownloadFileContentWithContext(context.Background(), file, w, config)
+}
+
+func (bot *BotAPI) DownloadFileContentWithContext(ctx context.Context, file File, w io.Writer, config DownloadConfig) (int64, error) {
+	if err := config.checkSize(file.FileSize); err != nil {
+		return 0, err
+	}
+
+	body, _, err := bot.openFileContent(ctx, file, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	return copyFileContent(ctx, w, body, config.limit(0))
+}
+
+// DownloadFileToPath gets the file with the given ID and saves it at path.
+//
+// The content is written to path with a ".part" suffix first and renamed once
+// it is complete. If a previous download left a partial file behind, the
+// download continues where it stopped. A partial file of the full size is
+// only kept if the server confirms that nothing is left after it, otherwise
+// the file is downloaded again.
+func (bot *BotAPI) DownloadFileToPath(fileID, path string, config DownloadConfig) (File, error) {
+	return bot.DownloadFileToPathWithContext(context.Background(), fileID, path, config)
+}
+
+func (bot *BotAPI) DownloadFileToPathWithContext(ctx context.Context, fileID, path string, config DownloadConfig) (File, error) {
+	file, err := bot.GetFileWithContext(ctx, FileConfig{FileID: fileID})
+	if err != nil {
+		return file, err
+	}
+	if err := config.checkSize(file.FileSize); err != nil {
+		return file, err
+	}
+
+	partPath := path + ".part"
+	part, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return file, err
+	}
+	defer part.Close()
+
+	offset, err := part.Seek(0, io.SeekEnd)
+	if err != nil {
+		return file, err
+	}
+	if file.FileSize > 0 && offset > file.FileSize {
+		offset = 0
+	}
+
+	err = bot.resumeFileContent(ctx, file, part, offset, config)
+	if errors.Is(err, ErrFileTooLarge) {
+		part.Close()
+		os.Remove(partPath)
+	}
+	if err != nil {
+		return file, err
+	}
+
+	if err := part.Sync(); err != nil {
+		return file, err
+	}
+	if err := part.Close(); err != nil {
+		return file, err
+	}
+
+	return file, os.Rename(partPath, path)
+}
+
+func (bot *BotAPI) resumeFileContent(ctx context.Context, file File, part *os.File, offset int64, config DownloadConfig) error {
+	body, resumed, err := bot.openFileContent(ctx, file, offset)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if !resumed {
+		offset = 0
+	}
+	if err := part.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := part.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = copyFileContent(ctx, part, body, config.limit(offset))
+	return err
+}
+
+// openFileContent opens the content of file starting at offset. It reports
+// whether the content starts at offset or at the beginning of the file.
+//
+// If offset is the size of the file and the server reports that no content
+// is left after it, the returned content is empty.
+func (bot *BotAPI) openFileContent(ctx context.Context, file File, offset int64) (io.ReadCloser, bool, error) {
+	if file.FilePath == "" {
+		return nil, false, errors.New("file has no path to download from")
+	}
+
+	if path, ok := localFilePath(file.FilePath); ok {
+		content, err := os.Open(path)
+		if err != nil {
+			return nil, false, err
+		}
+		if _, err := content.Seek(offset, io.SeekStart); err != nil {
+			content.Close()
+			return nil, false, err
+		}
+		return content, true, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bot.FileURL(file), nil)
+	if err != nil {
+		return nil, false, bot.redactToken(err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		return nil, false, bot.redactToken(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return resp.Body, false, nil
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		return resp.Body, true, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		resp.Body.Close()
+		if offset == file.FileSize {
+			return http.NoBody, true, nil
+		}
+		return bot.openFileContent(ctx, file, 0)
+	default:
+		resp.Body.Close()
+		return nil, false, fmt.Errorf("download file: unexpected status %s", resp.Status)
+	}
+}
+
+// redactToken removes the bot token from the URL of a request error, so it
+// does not end up in logs.
+func (bot *BotAPI) redactToken(err error) error {
+	var urlErr *url.Error
+	if bot.Token == "" || !errors.As(err, &urlErr) {
+		return err
+	}
+
+	redacted := *urlErr
+	redacted.URL = strings.ReplaceAll(redacted.URL, bot.Token, "<token>")
+	return &redacted
+}
+
+func (config DownloadConfig) checkSize(size int64) error {
+	if config.MaxSize > 0 && size > config.MaxSize {
+		return fmt.Errorf("%w: %d bytes", ErrFileTooLarge, size)
+	}
+	return nil
+}
+
+// limit returns the number of bytes that may be read after offset bytes were
+// already downloaded, or -1 if there is no limit.
+func (config DownloadConfig) limit(offset int64) int64 {
+	if config.MaxSize <= 0 {
+		return -1
+	}
+	return max(config.MaxSize-offset, 0)
+}
+
+// copyFileContent copies src to dst until EOF or ctx is done. It fails with
+// ErrFileTooLarge without writing more than limit bytes if src is longer. A
+// negative limit disables the check.
+func copyFileContent(ctx context.Context, dst io.Writer, src io.Reader, limit int64) (int64, error) {
+	src = contextReader{ctx: ctx, r: src}
+	if limit < 0 {
+		return io.Copy(dst, src)
+	}
+
+	written, err := io.Copy(dst, io.LimitReader(src, limit))
+	if err != nil {
+		return written, err
+	}
+
+	var probe [1]byte
+	n, err := io.ReadFull(src, probe[:])
+	if n > 0 {
+		return written, ErrFileTooLarge
+	}
+	if err != io.EOF {
+		return written, err
+	}
+	return written, nil
+}
+
+// localFilePath returns the path of a file stored by a local Bot API server,
+// which returns absolute paths instead of paths relative to the file endpoint.
+func localFilePath(filePath string) (string, bool) {
+	filePath = strings.TrimPrefix(filePath, "file://")
+	return filePath, filepath.IsAbs(filePath)
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,204 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const downloadContent = "0123456789abcdef"
+
+// newDownloadBot returns a bot serving a file at filePath with the given
+// content. The file endpoint honours Range headers.
+func newDownloadBot(t *testing.T, filePath string, size int, content string) (*BotAPI, *[]string) {
+	t.Helper()
+
+	var ranges []string
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/getFile") {
+				body := fmt.Sprintf(`{"ok":true,"result":{"file_id":"id","file_unique_id":"unique","file_size":%d,"file_path":%q}}`, size, filePath)
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+			}
+
+			if req.URL.Path != "/file/bottoken/"+filePath {
+				t.Errorf("unexpected download path %s", req.URL.Path)
+			}
+
+			header := req.Header.Get("Range")
+			ranges = append(ranges, header)
+			if header == "" {
+				return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(content))}, nil
+			}
+
+			offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, "bytes="), "-"))
+			if err != nil {
+				t.Fatalf("unexpected range %q", header)
+			}
+			if offset >= len(content) {
+				return &http.Response{StatusCode: http.StatusRequestedRangeNotSatisfiable, Status: "416 Requested Range Not Satisfiable", Body: http.NoBody}, nil
+			}
+			return &http.Response{StatusCode: http.StatusPartialContent, Status: "206 Partial Content", Body: io.NopCloser(strings.NewReader(content[offset:]))}, nil
+		},
+	})
+	bot.fileEndpoint = "https://example.com/file/bot%s/%s"
+
+	return bot, &ranges
+}
+
+func TestDownloadFile(t *testing.T) {
+	bot, _ := newDownloadBot(t, "photos/file.jpg", len(downloadContent), downloadContent)
+
+	var buf bytes.Buffer
+	file, err := bot.DownloadFile("id", &buf, DownloadConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.FilePath != "photos/file.jpg" || buf.String() != downloadContent {
+		t.Fatalf("unexpected download %#v %q", file, buf.String())
+	}
+}
+
+func TestDownloadFileMaxSize(t *testing.T) {
+	bot, ranges := newDownloadBot(t, "photos/file.jpg", len(downloadContent), downloadContent)
+
+	_, err := bot.DownloadFile("id", io.Discard, DownloadConfig{MaxSize: 8})
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+	if len(*ranges) != 0 {
+		t.Fatal("file with a known size above the limit was downloaded")
+	}
+
+	// The size reported by getFile may be missing, so the limit is also
+	// enforced while streaming.
+	bot, _ = newDownloadBot(t, "photos/file.jpg", 0, downloadContent)
+	var buf bytes.Buffer
+	_, err = bot.DownloadFile("id", &buf, DownloadConfig{MaxSize: 8})
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+	if buf.Len() > 8 {
+		t.Fatalf("wrote %d bytes over the limit", buf.Len())
+	}
+}
+
+func TestDownloadFileToPathResumes(t *testing.T) {
+	bot, ranges := newDownloadBot(t, "documents/file.txt", len(downloadContent), downloadContent)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path+".part", []byte(downloadContent[:6]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bot.DownloadFileToPath("id", path, DownloadConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != downloadContent {
+		t.Fatalf("unexpected content %q", data)
+	}
+	if len(*ranges) != 1 || (*ranges)[0] != "bytes=6-" {
+		t.Fatalf("unexpected ranges %q", *ranges)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Fatalf("partial file was not renamed: %v", err)
+	}
+}
+
+func TestDownloadFileToPathVerifiesCompletePart(t *testing.T) {
+	bot, ranges := newDownloadBot(t, "documents/file.txt", len(downloadContent), downloadContent)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path+".part", []byte(downloadContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bot.DownloadFileToPath("id", path, DownloadConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*ranges) != 1 || (*ranges)[0] != "bytes=16-" {
+		t.Fatalf("unexpected ranges %q", *ranges)
+	}
+
+	// A server ignoring the range sends the whole file, which replaces the
+	// partial file.
+	stale := strings.Repeat("x", len(downloadContent))
+	if err := os.WriteFile(path+".part", []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bot = newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/getFile") {
+				body := fmt.Sprintf(`{"ok":true,"result":{"file_id":"id","file_unique_id":"unique","file_size":%d,"file_path":"documents/file.txt"}}`, len(downloadContent))
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(downloadContent))}, nil
+		},
+	})
+	bot.fileEndpoint = "https://example.com/file/bot%s/%s"
+
+	if _, err := bot.DownloadFileToPath("id", path, DownloadConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != downloadContent {
+		t.Fatalf("unexpected content %q", data)
+	}
+}
+
+func TestDownloadFileLocalPath(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "file.jpg")
+	if err := os.WriteFile(local, []byte(downloadContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if !strings.HasSuffix(req.URL.Path, "/getFile") {
+				t.Fatalf("unexpected request to %s", req.URL)
+			}
+			body := fmt.Sprintf(`{"ok":true,"result":{"file_id":"id","file_unique_id":"unique","file_path":%q}}`, local)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	})
+
+	var buf bytes.Buffer
+	if _, err := bot.DownloadFile("id", &buf, DownloadConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != downloadContent {
+		t.Fatalf("unexpected content %q", buf.String())
+	}
+}
+
+func TestDownloadFileRedactsToken(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: errors.New("connection refused")}
+		},
+	})
+	bot.Token = "123:secret"
+	bot.fileEndpoint = "https://example.com/file/bot%s/%s"
+
+	_, err := bot.DownloadFileContentWithContext(context.Background(), File{FilePath: "photos/file.jpg"}, io.Discard, DownloadConfig{})
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected error without token, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package tgbotapi
+
+import (
+	"unicode/utf16"
+)
+
+// UTF16Len returns the length of s in UTF-16 code units, the unit used by
+// the offsets and lengths of MessageEntity.
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// UTF16Index converts an offset in UTF-16 code units into a byte index of s.
+//
+// It reports false if the offset is outside s or points into the middle of a
+// character encoded as a surrogate pair.
+func UTF16Index(s string, offset int) (int, bool) {
+	if offset < 0 {
+		return 0, false
+	}
+
+	units := 0
+	for i, r := range s {
+		if units == offset {
+			return i, true
+		}
+		units += utf16.RuneLen(r)
+		if units > offset {
+			return 0, false
+		}
+	}
+
+	if units == offset {
+		return len(s), true
+	}
+	return 0, false
+}
+
+// UTF16Offset converts a byte index of s into an offset in UTF-16 code units.
+// Indexes beyond the end of s are treated as the end of s.
+func UTF16Offset(s string, index int) int {
+	return UTF16Len(s[:min(max(index, 0), len(s))])
+}
+
+// UTF16Slice returns the part of s starting at offset and spanning length
+// UTF-16 code units.
+//
+// It reports false if the range is outside s or splits a surrogate pair.
+func UTF16Slice(s string, offset, length int) (string, bool) {
+	start, ok := UTF16Index(s, offset)
+	if !ok || length < 0 {
+		return "", false
+	}
+
+	end, ok := UTF16Index(s[start:], length)
+	if !ok {
+		return "", false
+	}
+
+	return s[start : start+end], true
+}
+
+// Text returns the part of text covered by the entity, or an empty string if
+// the entity does not fit into text.
+//
+// The text must be the one the entity belongs to, such as Message.Text for
+// Message.Entities and Message.Caption for Message.CaptionEntities.
+func (e MessageEntity) Text(text string) string {
+	s, _ := UTF16Slice(text, e.Offset, e.Length)
+	return s
+}
+
+// EntityText returns the part of the message text, or the caption for
+// messages without text, covered by the entity.
+func (m *Message) EntityText(entity MessageEntity) string {
+	text, _ := m.textWithEntities()
+	return entity.Text(text)
+}
+
+// EntityTexts returns the parts of the message text, or the caption for
+// messages without text, covered by entities of the given type, such as
+// "mention" or "hashtag".
+func (m *Message) EntityTexts(entityType string) []string {
+	text, entities := m.textWithEntities()
+
+	var texts []string
+	for _, entity := range entities {
+		if entity.Type == entityType {
+			texts = append(texts, entity.Text(text))
+		}
+	}
+	return texts
+}
+
+// URLs returns the links in the message text, or the caption for messages
+// without text. It contains the text of "url" entities and the URL of
+// "text_link" entities.
+func (m *Message) URLs() []string {
+	text, entities := m.textWithEntities()
+
+	var urls []string
+	for _, entity := range entities {
+		switch entity.Type {
+		case "url":
+			urls = append(urls, entity.Text(text))
+		case "text_link":
+			urls = append(urls, entity.URL)
+		}
+	}
+	return urls
+}
+
+func (m *Message) textWithEntities() (string, []MessageEntity) {
+	if m.Text == "" && m.Caption != "" {
+		return m.Caption, m.CaptionEntities
+	}
+	return m.Text, m.Entities
+}
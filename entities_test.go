@@ -0,0 +1,85 @@
+package tgbotapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUTF16Conversions(t *testing.T) {
+	const text = "a😀b€c"
+
+	if n := UTF16Len(text); n != 6 {
+		t.Fatalf("expected 6 code units, got %d", n)
+	}
+
+	tests := []struct {
+		offset int
+		index  int
+		ok     bool
+	}{
+		{offset: 0, index: 0, ok: true},
+		{offset: 1, index: 1, ok: true},
+		{offset: 2, ok: false},
+		{offset: 3, index: 5, ok: true},
+		{offset: 4, index: 6, ok: true},
+		{offset: 5, index: 9, ok: true},
+		{offset: 6, index: 10, ok: true},
+		{offset: 7, ok: false},
+		{offset: -1, ok: false},
+	}
+	for _, test := range tests {
+		index, ok := UTF16Index(text, test.offset)
+		if ok != test.ok || (ok && index != test.index) {
+			t.Errorf("UTF16Index(%d) = %d, %v; expected %d, %v", test.offset, index, ok, test.index, test.ok)
+		}
+		if test.ok && UTF16Offset(text, test.index) != test.offset {
+			t.Errorf("UTF16Offset(%d) = %d; expected %d", test.index, UTF16Offset(text, test.index), test.offset)
+		}
+	}
+
+	if s, ok := UTF16Slice(text, 1, 3); !ok || s != "😀b" {
+		t.Fatalf("unexpected slice %q, %v", s, ok)
+	}
+	if _, ok := UTF16Slice(text, 1, 1); ok {
+		t.Fatal("expected slice splitting a surrogate pair to fail")
+	}
+}
+
+func TestMessageEntityTexts(t *testing.T) {
+	message := Message{
+		Text: "Привет 👋 @gopher see #go at example.com or here",
+		Entities: []MessageEntity{
+			{Type: "mention", Offset: 10, Length: 7},
+			{Type: "hashtag", Offset: 22, Length: 3},
+			{Type: "url", Offset: 29, Length: 11},
+			{Type: "text_link", Offset: 44, Length: 4, URL: "https://go.dev"},
+		},
+	}
+
+	if mentions := message.EntityTexts("mention"); !reflect.DeepEqual(mentions, []string{"@gopher"}) {
+		t.Fatalf("unexpected mentions %q", mentions)
+	}
+	if hashtags := message.EntityTexts("hashtag"); !reflect.DeepEqual(hashtags, []string{"#go"}) {
+		t.Fatalf("unexpected hashtags %q", hashtags)
+	}
+	if urls := message.URLs(); !reflect.DeepEqual(urls, []string{"example.com", "https://go.dev"}) {
+		t.Fatalf("unexpected URLs %q", urls)
+	}
+	if text := message.EntityText(message.Entities[3]); text != "here" {
+		t.Fatalf("unexpected text link text %q", text)
+	}
+}
+
+func TestMessageEntityTextUsesCaption(t *testing.T) {
+	message := Message{
+		Caption:         "photo #tag",
+		CaptionEntities: []MessageEntity{{Type: "hashtag", Offset: 6, Length: 4}},
+	}
+
+	if hashtags := message.EntityTexts("hashtag"); !reflect.DeepEqual(hashtags, []string{"#tag"}) {
+		t.Fatalf("unexpected hashtags %q", hashtags)
+	}
+	if text := (MessageEntity{Offset: 8, Length: 10}).Text(message.Caption); text != "" {
+		t.Fatalf("expected empty text for entity outside text, got %q", text)
+	}
+}
@@ -0,0 +1,84 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Common Telegram API failures. An *Error returned by the API matches them
+// with errors.Is:
+//
+//	if errors.Is(err, tgbotapi.ErrBotBlocked) {
+//		// stop messaging the user
+//	}
+//
+// Use errors.As with *Error to get the description and ResponseParameters,
+// such as RetryAfter or MigrateToChatID.
+var (
+	// ErrBotBlocked means the user has blocked the bot.
+	ErrBotBlocked = errors.New("bot was blocked by the user")
+	// ErrChatNotFound means the chat doesn't exist or the bot can't access it.
+	ErrChatNotFound = errors.New("chat not found")
+	// ErrMessageNotModified means an edit didn't change the message.
+	ErrMessageNotModified = errors.New("message is not modified")
+	// ErrMessageToEditNotFound means the message to edit doesn't exist.
+	ErrMessageToEditNotFound = errors.New("message to edit not found")
+	// ErrTooManyRequests means the request was rate limited. RetryAfter of
+	// the *Error tells how long to wait.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrChatMigrated means the group was upgraded to a supergroup.
+	// MigrateToChatID of the *Error is the ID of the supergroup.
+	ErrChatMigrated = errors.New("group chat was upgraded to a supergroup chat")
+	// ErrUnauthorized means the bot token is invalid or was revoked.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInvalidFileID means a file ID is invalid or can no longer be used.
+	ErrInvalidFileID = errors.New("wrong file identifier")
+	// ErrCantParseEntities means formatted text couldn't be parsed. It also
+	// matches an *EntityParseError returned by ParseMarkup.
+	ErrCantParseEntities = errors.New("can't parse entities")
+)
+
+// Is reports whether the error is one of the common failures, such as
+// ErrBotBlocked.
+func (e Error) Is(target error) bool {
+	description := strings.ToLower(e.Message)
+
+	switch target {
+	case ErrBotBlocked:
+		return e.Code == http.StatusForbidden && strings.Contains(description, "bot was blocked by the user")
+	case ErrChatNotFound:
+		return strings.Contains(description, "chat not found")
+	case ErrMessageNotModified:
+		return strings.Contains(description, "message is not modified")
+	case ErrMessageToEditNotFound:
+		return strings.Contains(description, "message to edit not found")
+	case ErrTooManyRequests:
+		return e.Code == http.StatusTooManyRequests
+	case ErrChatMigrated:
+		return e.MigrateToChatID != 0 || strings.Contains(description, "upgraded to a supergroup")
+	case ErrUnauthorized:
+		return e.Code == http.StatusUnauthorized
+	case ErrInvalidFileID:
+		return e.Code == http.StatusBadRequest &&
+			(strings.Contains(description, "file identifier") || strings.Contains(description, "file reference"))
+	case ErrCantParseEntities:
+		return strings.Contains(description, "can't parse entities")
+	}
+	return false
+}
+
+// Is reports whether target is ErrCantParseEntities.
+func (e *EntityParseError) Is(target error) bool {
+	return target == ErrCantParseEntities
+}
+
+// decodeResult decodes the result of a request to method into v.
+func decodeResult(method string, result json.RawMessage, v any) error {
+	if err := json.Unmarshal(result, v); err != nil {
+		return fmt.Errorf("%s: decode result: %w", method, err)
+	}
+	return nil
+}
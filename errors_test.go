@@ -0,0 +1,106 @@
+package tgbotapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		err    *Error
+		target error
+	}{
+		{&Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}, ErrBotBlocked},
+		{&Error{Code: 400, Message: "Bad Request: chat not found"}, ErrChatNotFound},
+		{&Error{Code: 400, Message: "Bad Request: message is not modified: specified new message content and reply markup are exactly the same"}, ErrMessageNotModified},
+		{&Error{Code: 400, Message: "Bad Request: message to edit not found"}, ErrMessageToEditNotFound},
+		{&Error{Code: 429, Message: "Too Many Requests: retry after 5", ResponseParameters: ResponseParameters{RetryAfter: 5}}, ErrTooManyRequests},
+		{&Error{Code: 400, Message: "Bad Request: group chat was upgraded to a supergroup chat", ResponseParameters: ResponseParameters{MigrateToChatID: -100}}, ErrChatMigrated},
+		{&Error{Code: 401, Message: "Unauthorized"}, ErrUnauthorized},
+		{&Error{Code: 400, Message: "Bad Request: wrong file identifier/HTTP URL specified"}, ErrInvalidFileID},
+		{&Error{Code: 400, Message: "Bad Request: can't parse entities: Unsupported start tag \"foo\" at byte offset 0"}, ErrCantParseEntities},
+	}
+
+	targets := make([]error, len(tests))
+	for i, test := range tests {
+		targets[i] = test.target
+	}
+
+	for i, test := range tests {
+		for j, target := range targets {
+			if got := errors.Is(test.err, target); got != (i == j) {
+				t.Errorf("%q: errors.Is(%v) = %v", test.err.Message, target, got)
+			}
+		}
+	}
+
+	if !errors.Is(newEntityParseError(0, "x"), ErrCantParseEntities) {
+		t.Error("expected a local parse error to match ErrCantParseEntities")
+	}
+}
+
+func TestRequestErrorsAreClassified(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Body:       io.NopCloser(strings.NewReader(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)),
+			}, nil
+		},
+	})
+
+	_, err := bot.Send(NewMessage(1, "hello"))
+	if !errors.Is(err, ErrBotBlocked) {
+		t.Fatalf("expected ErrBotBlocked, got %v", err)
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		t.Fatalf("expected an *Error, got %#v", err)
+	}
+}
+
+func TestRequestErrorsIncludeEndpoint(t *testing.T) {
+	transportErr := errors.New("connection refused")
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/getMe") {
+				return nil, transportErr
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`<html>`)),
+			}, nil
+		},
+	})
+
+	_, err := bot.GetMe()
+	if !errors.Is(err, transportErr) || !strings.HasPrefix(err.Error(), "getMe: ") {
+		t.Fatalf("unexpected transport error %v", err)
+	}
+
+	_, err = bot.Send(NewMessage(1, "hello"))
+	if !strings.HasPrefix(err.Error(), "sendMessage: decode response: ") {
+		t.Fatalf("unexpected decode error %v", err)
+	}
+
+	bot.Client = fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			return resultResponse(`"not a message"`), nil
+		},
+	}
+	_, err = bot.Send(NewMessage(1, "hello"))
+	if !strings.HasPrefix(err.Error(), "sendMessage: decode result: ") {
+		t.Fatalf("unexpected result error %v", err)
+	}
+	_, err = bot.GetChat(ChatInfoConfig{ChatConfig: ChatConfig{ChatID: 1}})
+	if err == nil || !strings.HasPrefix(err.Error(), "getChat: decode result: ") {
+		t.Fatalf("unexpected getChat result error %v", err)
+	}
+	_, err = bot.GetWebhookInfo()
+	if err == nil || !strings.HasPrefix(err.Error(), "getWebhookInfo: decode result: ") {
+		t.Fatalf("unexpected getWebhookInfo result error %v", err)
+	}
+}
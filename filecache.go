@@ -0,0 +1,271 @@
+package tgbotapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CachedFile is a file that is uploaded once and then sent by its file ID.
+//
+// Files are only cached by requests passing through the CacheFileIDs request
+// middleware; otherwise the wrapped File is uploaded every time. FilePath
+// files are cached by path, size and modification time, and FileBytes files
+// by a hash of their content. Other files are not cached.
+//
+// Only the main file of a message, such as the photo of sendPhoto, is cached.
+// Thumbnails and media groups are always uploaded.
+type CachedFile struct {
+	File RequestFileData
+}
+
+func (cf CachedFile) NeedsUpload() bool {
+	return cf.File.NeedsUpload()
+}
+
+func (cf CachedFile) UploadData() (string, io.Reader, error) {
+	return cf.File.UploadData()
+}
+
+func (cf CachedFile) SendData() string {
+	return cf.File.SendData()
+}
+
+// cacheKey returns the key identifying the content of the file, or an empty
+// key if the file can't be cached.
+func (cf CachedFile) cacheKey() (string, error) {
+	switch file := cf.File.(type) {
+	case FilePath:
+		path, err := filepath.Abs(string(file))
+		if err != nil {
+			return "", err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("path:%s:%d:%d", path, info.Size(), info.ModTime().UnixNano()), nil
+	case FileBytes:
+		sum := sha256.Sum256(file.Bytes)
+		return "sha256:" + hex.EncodeToString(sum[:]), nil
+	}
+	return "", nil
+}
+
+// FileIDStore persists the file IDs of cached files.
+type FileIDStore interface {
+	// LoadFileID returns the file ID stored for key, or an empty string if
+	// none was stored.
+	LoadFileID(ctx context.Context, key string) (string, error)
+	// SaveFileID stores the file ID for key.
+	SaveFileID(ctx context.Context, key, fileID string) error
+	// DeleteFileID removes the file ID stored for key.
+	DeleteFileID(ctx context.Context, key string) error
+}
+
+// MemoryFileIDStore keeps file IDs in memory.
+type MemoryFileIDStore struct {
+	mu      sync.Mutex
+	fileIDs map[string]string
+}
+
+// NewMemoryFileIDStore creates an empty MemoryFileIDStore.
+func NewMemoryFileIDStore() *MemoryFileIDStore {
+	return &MemoryFileIDStore{fileIDs: make(map[string]string)}
+}
+
+// LoadFileID returns the file ID stored for key.
+func (s *MemoryFileIDStore) LoadFileID(_ context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fileIDs[key], nil
+}
+
+// SaveFileID stores the file ID for key.
+func (s *MemoryFileIDStore) SaveFileID(_ context.Context, key, fileID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fileIDs[key] = fileID
+	return nil
+}
+
+// DeleteFileID removes the file ID stored for key.
+func (s *MemoryFileIDStore) DeleteFileID(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.fileIDs, key)
+	return nil
+}
+
+// cachedFileFields are the request fields whose files are cached.
+var cachedFileFields = map[string]bool{
+	"photo":      true,
+	"document":   true,
+	"video":      true,
+	"audio":      true,
+	"animation":  true,
+	"voice":      true,
+	"video_note": true,
+	"sticker":    true,
+}
+
+// cachedUpload is a CachedFile in a request.
+type cachedUpload struct {
+	name   string
+	key    string
+	fileID string
+}
+
+// CacheFileIDs returns request middleware that sends each CachedFile by the
+// file ID Telegram returned for its first upload, using store to keep the
+// IDs.
+//
+// If Telegram rejects a cached file ID, the ID is removed from store and the
+// request is sent again with the files uploaded, unless the request also
+// uploaded a FileReader, which can't be read again. Store errors are treated
+// as cache misses, so a failing store doesn't prevent sending files.
+func CacheFileIDs(store FileIDStore) RequestMiddleware {
+	return func(next RequestHandlerFunc) RequestHandlerFunc {
+		return func(ctx context.Context, request APIRequest) (*APIResponse, error) {
+			uploads := loadCachedUploads(ctx, store, request.Files)
+			if len(uploads) == 0 {
+				return next(ctx, request)
+			}
+
+			cachedRequest := withCachedFileIDs(request, uploads)
+			resp, err := next(ctx, cachedRequest)
+			if err != nil && errors.Is(err, ErrInvalidFileID) && hasCachedFileIDs(uploads) {
+				for i := range uploads {
+					if uploads[i].fileID != "" {
+						_ = store.DeleteFileID(ctx, uploads[i].key)
+						uploads[i].fileID = ""
+					}
+				}
+
+				// The files uploaded with the cached IDs were already read.
+				if !filesReplayable(cachedRequest.Files) {
+					return resp, err
+				}
+				resp, err = next(ctx, request)
+			}
+			if err != nil {
+				return resp, err
+			}
+
+			saveCachedUploads(ctx, store, uploads, resp)
+			return resp, nil
+		}
+	}
+}
+
+func loadCachedUploads(ctx context.Context, store FileIDStore, files []RequestFile) []cachedUpload {
+	var uploads []cachedUpload
+	for _, file := range files {
+		cached, ok := file.Data.(CachedFile)
+		if !ok || !cachedFileFields[file.Name] || !cached.NeedsUpload() {
+			continue
+		}
+
+		key, err := cached.cacheKey()
+		if err != nil || key == "" {
+			continue
+		}
+		key = file.Name + ":" + key
+
+		fileID, err := store.LoadFileID(ctx, key)
+		if err != nil {
+			fileID = ""
+		}
+		uploads = append(uploads, cachedUpload{name: file.Name, key: key, fileID: fileID})
+	}
+	return uploads
+}
+
+// withCachedFileIDs returns the request with the files that have a cached
+// file ID sent by their ID.
+func withCachedFileIDs(request APIRequest, uploads []cachedUpload) APIRequest {
+	if !hasCachedFileIDs(uploads) {
+		return request
+	}
+
+	params := make(Params, len(request.Params)+len(uploads))
+	for key, value := range request.Params {
+		params[key] = value
+	}
+
+	var files []RequestFile
+	for _, file := range request.Files {
+		cached := false
+		for _, upload := range uploads {
+			if upload.name == file.Name && upload.fileID != "" {
+				params[file.Name] = upload.fileID
+				cached = true
+			}
+		}
+		if !cached {
+			files = append(files, file)
+		}
+	}
+
+	request.Params, request.Files = params, files
+	return request
+}
+
+func hasCachedFileIDs(uploads []cachedUpload) bool {
+	for _, upload := range uploads {
+		if upload.fileID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// saveCachedUploads stores the file IDs of the files uploaded by a request.
+func saveCachedUploads(ctx context.Context, store FileIDStore, uploads []cachedUpload, resp *APIResponse) {
+	var message Message
+	if resp == nil || json.Unmarshal(resp.Result, &message) != nil {
+		return
+	}
+
+	for _, upload := range uploads {
+		if upload.fileID != "" {
+			continue
+		}
+		if fileID := messageFileID(&message, upload.name); fileID != "" {
+			_ = store.SaveFileID(ctx, upload.key, fileID)
+		}
+	}
+}
+
+// messageFileID returns the ID of the file sent in the request field name.
+func messageFileID(message *Message, name string) string {
+	switch {
+	case name == "photo" && len(message.Photo) > 0:
+		return message.Photo[len(message.Photo)-1].FileID
+	case name == "document" && message.Document != nil:
+		return message.Document.FileID
+	case name == "video" && message.Video != nil:
+		return message.Video.FileID
+	case name == "audio" && message.Audio != nil:
+		return message.Audio.FileID
+	case name == "animation" && message.Animation != nil:
+		return message.Animation.FileID
+	case name == "voice" && message.Voice != nil:
+		return message.Voice.FileID
+	case name == "video_note" && message.VideoNote != nil:
+		return message.VideoNote.FileID
+	case name == "sticker" && message.Sticker != nil:
+		return message.Sticker.FileID
+	}
+	return ""
+}
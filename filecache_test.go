@@ -0,0 +1,144 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCacheFileIDs(t *testing.T) {
+	var uploads, photos []string
+	stale := ""
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+				if err := req.ParseMultipartForm(1 << 20); err != nil {
+					return nil, err
+				}
+				uploads = append(uploads, req.MultipartForm.File["photo"][0].Filename)
+				id := "id-" + string(rune('0'+len(uploads)))
+				return resultResponse(`{"message_id":1,"photo":[{"file_id":"small"},{"file_id":"` + id + `"}]}`), nil
+			}
+
+			if err := req.ParseForm(); err != nil {
+				return nil, err
+			}
+			photo := req.PostForm.Get("photo")
+			photos = append(photos, photo)
+			if photo == stale {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body:       io.NopCloser(strings.NewReader(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`)),
+				}, nil
+			}
+			return resultResponse(`{"message_id":2}`), nil
+		},
+	})
+	store := NewMemoryFileIDStore()
+	bot.requestMiddleware = []RequestMiddleware{CacheFileIDs(store)}
+
+	photo := NewPhoto(1, CachedFile{File: FileBytes{Name: "logo.png", Bytes: []byte("logo")}})
+	for i := 0; i < 2; i++ {
+		if _, err := bot.Send(photo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(uploads) != 1 || len(photos) != 1 || photos[0] != "id-1" {
+		t.Fatalf("expected one upload and one send by ID, got %q and %q", uploads, photos)
+	}
+
+	// Different content is uploaded again.
+	other := NewPhoto(1, CachedFile{File: FileBytes{Name: "other.png", Bytes: []byte("other")}})
+	if _, err := bot.Send(other); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 2 {
+		t.Fatalf("expected a new upload for other content, got %q", uploads)
+	}
+
+	// A rejected ID is replaced by a new upload.
+	stale = "id-1"
+	if _, err := bot.Send(photo); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 3 || uploads[2] != "logo.png" {
+		t.Fatalf("expected the stale file to be uploaded again, got %q", uploads)
+	}
+	key := "photo:" + mustCacheKey(t, photo.File.(CachedFile))
+	if fileID, _ := store.LoadFileID(context.Background(), key); fileID != "id-3" {
+		t.Fatalf("expected the new file ID to be stored, got %q", fileID)
+	}
+}
+
+func TestCacheFileIDsKeepsReaderUploads(t *testing.T) {
+	requests := 0
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			requests++
+			if err := req.ParseMultipartForm(1 << 20); err != nil {
+				return nil, err
+			}
+			if len(req.MultipartForm.File["thumbnail"]) != 1 || req.PostForm.Get("document") != "stale" {
+				t.Errorf("expected the thumbnail with the cached document, got %v", req.MultipartForm)
+			}
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(strings.NewReader(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`)),
+			}, nil
+		},
+	})
+	store := NewMemoryFileIDStore()
+	bot.requestMiddleware = []RequestMiddleware{CacheFileIDs(store)}
+
+	document := NewDocument(1, CachedFile{File: FileBytes{Name: "report.pdf", Bytes: []byte("report")}})
+	document.Thumb = FileReader{Name: "thumb.jpg", Reader: strings.NewReader("thumbnail")}
+	key := "document:" + mustCacheKey(t, document.File.(CachedFile))
+	if err := store.SaveFileID(context.Background(), key, "stale"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := bot.Send(document)
+	if !errors.Is(err, ErrInvalidFileID) {
+		t.Fatalf("expected ErrInvalidFileID, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected the read thumbnail not to be sent again, got %d requests", requests)
+	}
+	if fileID, _ := store.LoadFileID(context.Background(), key); fileID != "" {
+		t.Fatalf("expected the stale file ID to be removed, got %q", fileID)
+	}
+}
+
+func TestCachedFilePathKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("a"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file := CachedFile{File: FilePath(path)}
+	first := mustCacheKey(t, file)
+
+	if err := os.WriteFile(path, []byte("ab"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if mustCacheKey(t, file) == first {
+		t.Fatal("expected the key to change with the file")
+	}
+
+	if key, err := (CachedFile{File: FileURL("https://example.com")}).cacheKey(); err != nil || key != "" {
+		t.Fatalf("expected URLs not to be cached, got %q %v", key, err)
+	}
+}
+
+func mustCacheKey(t *testing.T, file CachedFile) string {
+	t.Helper()
+	key, err := file.cacheKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
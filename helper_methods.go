@@ -28,6 +28,16 @@ func NewMessage(chatID int64, text string) MessageConfig {
 	}
 }
 
+// NewFormattedMessage creates a new Message with the text and entities of a
+// TextBuilder.
+//
+// chatID is where to send it, text is the formatted message text.
+func NewFormattedMessage(chatID int64, text *TextBuilder) MessageConfig {
+	msg := NewMessage(chatID, text.String())
+	msg.Entities = text.Entities()
+	return msg
+}
+
 // NewInputRichMessageHTML creates a new HTML rich message input.
 func NewInputRichMessageHTML(html string) InputRichMessage {
 	return InputRichMessage{
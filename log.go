@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 )
 
 // BotLogger is an interface that represents the required methods to log data.
@@ -90,22 +91,41 @@ func (bot *BotAPI) logResponseDebug(ctx context.Context, endpoint string, respon
 	}
 }
 
-func (bot *BotAPI) logUpdateError(ctx context.Context, err error) {
+func (bot *BotAPI) logUpdateError(ctx context.Context, err error, delay time.Duration) {
 	if bot.loggingDisabled {
 		return
 	}
 	switch logger := bot.logger.(type) {
 	case BotLogger:
-		logger.Printf("[DEBUG] Failed to get updates (%s), retrying in 3 seconds...", err)
+		logger.Printf("[DEBUG] Failed to get updates (%s), retrying in %s...", err, delay)
 	case *slog.Logger:
 		logger.ErrorContext(ctx, "telegram get updates failed",
 			"error", err,
 		)
 		logger.InfoContext(ctx, "telegram get updates retry scheduled",
-			"delay", "3s",
+			"delay", delay.String(),
 		)
 	default:
-		log.Printf("[DEBUG] Failed to get updates (%s), retrying in 3 seconds...", err)
+		log.Printf("[DEBUG] Failed to get updates (%s), retrying in %s...", err, delay)
+	}
+}
+
+func (bot *BotAPI) logRetry(ctx context.Context, endpoint string, attempt int, delay time.Duration, err error) {
+	if bot.loggingDisabled {
+		return
+	}
+	switch logger := bot.logger.(type) {
+	case BotLogger:
+		logger.Printf("[WARN] Endpoint: %s, attempt %d failed (%s), retrying in %s...", endpoint, attempt, err, delay)
+	case *slog.Logger:
+		logger.WarnContext(ctx, "telegram request retry scheduled",
+			"endpoint", endpoint,
+			"attempt", attempt,
+			"delay", delay.String(),
+			"error", err,
+		)
+	default:
+		log.Printf("[WARN] Endpoint: %s, attempt %d failed (%s), retrying in %s...", endpoint, attempt, err, delay)
 	}
 }
 
@@ -0,0 +1,361 @@
+package tgbotapi
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// markupSpan is an entity with its bounds converted to byte indexes.
+type markupSpan struct {
+	start, end int
+	entity     MessageEntity
+}
+
+// markupRenderer renders text with nested entities as HTML or MarkdownV2.
+type markupRenderer struct {
+	mode  string
+	text  string
+	out   strings.Builder
+	pos   int
+	stack []markupSpan
+	// marker is set if the last output was markup rather than text.
+	marker bool
+}
+
+// EntitiesToHTML formats text with its entities for ModeHTML. It is the
+// inverse of sending text with ParseMode set to ModeHTML.
+//
+// Entities that overlap without being nested are split into parts. Entities
+// that do not fit into text are ignored, and entities without markup, such as
+// "url" or "mention", are left as plain text.
+func EntitiesToHTML(text string, entities []MessageEntity) string {
+	return renderMarkup(text, entities, ModeHTML)
+}
+
+// EntitiesToMarkdownV2 formats text with its entities for ModeMarkdownV2. It
+// is the inverse of sending text with ParseMode set to ModeMarkdownV2.
+//
+// Entities are handled as in EntitiesToHTML.
+func EntitiesToMarkdownV2(text string, entities []MessageEntity) string {
+	return renderMarkup(text, entities, ModeMarkdownV2)
+}
+
+// HTML returns the text of the message, or the caption for messages without
+// text, formatted for ModeHTML.
+func (m *Message) HTML() string {
+	return EntitiesToHTML(m.textWithEntities())
+}
+
+// MarkdownV2 returns the text of the message, or the caption for messages
+// without text, formatted for ModeMarkdownV2.
+func (m *Message) MarkdownV2() string {
+	return EntitiesToMarkdownV2(m.textWithEntities())
+}
+
+// renderMarkup renders text with entities as markup for the given parse
+// mode.
+func renderMarkup(text string, entities []MessageEntity, mode string) string {
+	r := markupRenderer{mode: mode, text: text}
+
+	spans := markupSpans(text, entities)
+	for i := 0; i < len(spans); i++ {
+		span := spans[i]
+		r.closeUntil(span.start)
+
+		// A span reaching past the innermost open span is split, and its
+		// remainder is opened again once the open span is closed.
+		if n := len(r.stack); n > 0 && span.end > r.stack[n-1].end {
+			rest := span
+			rest.start = r.stack[n-1].end
+			span.end = rest.start
+			spans = insertMarkupSpan(spans, i+1, rest)
+		}
+
+		r.writeText(span.start)
+		r.open(span)
+	}
+	r.closeUntil(len(text))
+	r.writeText(len(text))
+
+	return r.out.String()
+}
+
+// markupSpans converts entities into spans ordered by start, with outer spans
+// before the spans nested in them.
+func markupSpans(text string, entities []MessageEntity) []markupSpan {
+	spans := make([]markupSpan, 0, len(entities))
+	for _, entity := range entities {
+		if entity.Length <= 0 {
+			continue
+		}
+		start, ok := UTF16Index(text, entity.Offset)
+		if !ok {
+			continue
+		}
+		length, ok := UTF16Index(text[start:], entity.Length)
+		if !ok {
+			continue
+		}
+		spans = append(spans, markupSpan{start: start, end: start + length, entity: entity})
+	}
+
+	slices.SortStableFunc(spans, func(a, b markupSpan) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return b.end - a.end
+	})
+
+	return spans
+}
+
+// insertMarkupSpan inserts span into spans[from:] keeping the order of
+// markupSpans.
+func insertMarkupSpan(spans []markupSpan, from int, span markupSpan) []markupSpan {
+	i := from
+	for i < len(spans) && (spans[i].start < span.start || spans[i].start == span.start && spans[i].end >= span.end) {
+		i++
+	}
+	return slices.Insert(spans, i, span)
+}
+
+// closeUntil closes all open spans ending at or before pos.
+func (r *markupRenderer) closeUntil(pos int) {
+	for len(r.stack) > 0 {
+		top := r.stack[len(r.stack)-1]
+		if top.end > pos {
+			return
+		}
+		r.writeText(top.end)
+		r.stack = r.stack[:len(r.stack)-1]
+		r.writeMarker(r.closeTag(top.entity))
+	}
+}
+
+func (r *markupRenderer) open(span markupSpan) {
+	r.writeMarker(r.openTag(span.entity))
+	r.stack = append(r.stack, span)
+}
+
+// writeText writes the text up to end, escaped for the current context.
+func (r *markupRenderer) writeText(end int) {
+	if end <= r.pos {
+		return
+	}
+	text := r.text[r.pos:end]
+	r.pos = end
+
+	if r.mode == ModeHTML {
+		r.out.WriteString(escapeHTML(text))
+		r.marker = false
+		return
+	}
+
+	inCode, inQuote := false, false
+	for _, span := range r.stack {
+		switch span.entity.Type {
+		case "code", "pre":
+			inCode = true
+		case "blockquote", "expandable_blockquote":
+			inQuote = true
+		}
+	}
+
+	if inCode {
+		text = escapeMarkdownV2Code(text)
+	} else {
+		text = escapeMarkdownV2(text)
+	}
+	if inQuote {
+		text = strings.ReplaceAll(text, "\n", "\n>")
+	}
+
+	r.out.WriteString(text)
+	r.marker = false
+}
+
+func (r *markupRenderer) writeMarker(marker string) {
+	if marker == "" {
+		return
+	}
+
+	// In MarkdownV2 "__" is always read as underline, so adjacent italic and
+	// underline markers are separated by an ignored carriage return.
+	if r.mode != ModeHTML && r.marker && strings.HasSuffix(r.out.String(), "_") && marker[0] == '_' {
+		r.out.WriteByte('\r')
+	}
+
+	r.out.WriteString(marker)
+	r.marker = true
+}
+
+func (r *markupRenderer) openTag(entity MessageEntity) string {
+	if r.mode == ModeHTML {
+		switch entity.Type {
+		case "bold":
+			return "<b>"
+		case "italic":
+			return "<i>"
+		case "underline":
+			return "<u>"
+		case "strikethrough":
+			return "<s>"
+		case "spoiler":
+			return "<tg-spoiler>"
+		case "code":
+			return "<code>"
+		case "pre":
+			if entity.Language != "" {
+				return `<pre><code class="language-` + escapeHTMLAttribute(entity.Language) + `">`
+			}
+			return "<pre>"
+		case "text_link":
+			return `<a href="` + escapeHTMLAttribute(entity.URL) + `">`
+		case "text_mention":
+			return `<a href="` + textMentionURL(entity) + `">`
+		case "custom_emoji":
+			return `<tg-emoji emoji-id="` + escapeHTMLAttribute(entity.CustomEmojiID) + `">`
+		case "date_time":
+			tag := `<tg-time unix="` + strconv.FormatInt(entity.UnixTime, 10) + `"`
+			if entity.DateTimeFormat != "" {
+				tag += ` format="` + escapeHTMLAttribute(entity.DateTimeFormat) + `"`
+			}
+			return tag + ">"
+		case "blockquote":
+			return "<blockquote>"
+		case "expandable_blockquote":
+			return "<blockquote expandable>"
+		}
+		return ""
+	}
+
+	switch entity.Type {
+	case "bold":
+		return "*"
+	case "italic":
+		return "_"
+	case "underline":
+		return "__"
+	case "strikethrough":
+		return "~"
+	case "spoiler":
+		return "||"
+	case "code":
+		return "`"
+	case "pre":
+		return "```" + entity.Language + "\n"
+	case "text_link", "text_mention":
+		return "["
+	case "custom_emoji", "date_time":
+		return "!["
+	case "blockquote":
+		return ">"
+	case "expandable_blockquote":
+		return "**>"
+	}
+	return ""
+}
+
+func (r *markupRenderer) closeTag(entity MessageEntity) string {
+	if r.mode == ModeHTML {
+		switch entity.Type {
+		case "bold":
+			return "</b>"
+		case "italic":
+			return "</i>"
+		case "underline":
+			return "</u>"
+		case "strikethrough":
+			return "</s>"
+		case "spoiler":
+			return "</tg-spoiler>"
+		case "code":
+			return "</code>"
+		case "pre":
+			if entity.Language != "" {
+				return "</code></pre>"
+			}
+			return "</pre>"
+		case "text_link", "text_mention":
+			return "</a>"
+		case "custom_emoji":
+			return "</tg-emoji>"
+		case "date_time":
+			return "</tg-time>"
+		case "blockquote", "expandable_blockquote":
+			return "</blockquote>"
+		}
+		return ""
+	}
+
+	switch entity.Type {
+	case "bold":
+		return "*"
+	case "italic":
+		return "_"
+	case "underline":
+		return "__"
+	case "strikethrough":
+		return "~"
+	case "spoiler", "expandable_blockquote":
+		return "||"
+	case "code":
+		return "`"
+	case "pre":
+		return "```"
+	case "text_link":
+		return "](" + escapeMarkdownV2URL(entity.URL) + ")"
+	case "text_mention":
+		return "](" + textMentionURL(entity) + ")"
+	case "custom_emoji":
+		return "](tg://emoji?id=" + escapeMarkdownV2URL(entity.CustomEmojiID) + ")"
+	case "date_time":
+		link := "tg://time?unix=" + strconv.FormatInt(entity.UnixTime, 10)
+		if entity.DateTimeFormat != "" {
+			link += "&format=" + url.QueryEscape(entity.DateTimeFormat)
+		}
+		return "](" + escapeMarkdownV2URL(link) + ")"
+	}
+	return ""
+}
+
+func textMentionURL(entity MessageEntity) string {
+	if entity.User == nil {
+		return ""
+	}
+	return "tg://user?id=" + strconv.FormatInt(entity.User.ID, 10)
+}
+
+func escapeHTML(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func escapeHTMLAttribute(text string) string {
+	return html.EscapeString(text)
+}
+
+var (
+	markdownV2Escaper = strings.NewReplacer(
+		"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(",
+		")", "\\)", "~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+",
+		"-", "\\-", "=", "\\=", "|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.",
+		"!", "\\!",
+	)
+	markdownV2CodeEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+	markdownV2URLEscaper  = strings.NewReplacer("\\", "\\\\", ")", "\\)")
+)
+
+func escapeMarkdownV2(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
+
+func escapeMarkdownV2Code(text string) string {
+	return markdownV2CodeEscaper.Replace(text)
+}
+
+func escapeMarkdownV2URL(text string) string {
+	return markdownV2URLEscaper.Replace(text)
+}
@@ -0,0 +1,82 @@
+package tgbotapi
+
+import (
+	"testing"
+)
+
+func TestEntitiesToHTMLOverlapping(t *testing.T) {
+	// "bold" covers "ab", "italic" covers "bc".
+	entities := []MessageEntity{
+		{Type: "italic", Offset: 1, Length: 2},
+		{Type: "bold", Offset: 0, Length: 2},
+	}
+
+	if html := EntitiesToHTML("abc", entities); html != "<b>a<i>b</i></b><i>c</i>" {
+		t.Fatalf("unexpected HTML %q", html)
+	}
+	if markdown := EntitiesToMarkdownV2("abc", entities); markdown != "*a_b_*_c_" {
+		t.Fatalf("unexpected MarkdownV2 %q", markdown)
+	}
+}
+
+func TestEntitiesToHTMLOverlappingNested(t *testing.T) {
+	// "underline" starts inside "italic" and ends after "bold".
+	entities := []MessageEntity{
+		{Type: "bold", Offset: 0, Length: 4},
+		{Type: "italic", Offset: 1, Length: 2},
+		{Type: "underline", Offset: 2, Length: 3},
+	}
+
+	expected := "<b>a<i>b<u>c</u></i><u>d</u></b><u>e</u>"
+	if html := EntitiesToHTML("abcde", entities); html != expected {
+		t.Fatalf("unexpected HTML\n%s\n%s", html, expected)
+	}
+}
+
+func TestEntitiesToHTMLInvalidEntities(t *testing.T) {
+	entities := []MessageEntity{
+		{Type: "bold", Offset: 1, Length: 1},
+		{Type: "italic", Offset: 0, Length: 10},
+		{Type: "url", Offset: 0, Length: 2},
+	}
+
+	if html := EntitiesToHTML("😀!", entities); html != "😀!" {
+		t.Fatalf("unexpected HTML %q", html)
+	}
+}
+
+func TestMessageHTML(t *testing.T) {
+	message := Message{
+		Caption: "Hi Bob, see\nthis",
+		CaptionEntities: []MessageEntity{
+			{Type: "text_mention", Offset: 3, Length: 3, User: &User{ID: 7}},
+			{Type: "expandable_blockquote", Offset: 8, Length: 8},
+			{Type: "custom_emoji", Offset: 0, Length: 2, CustomEmojiID: "5"},
+		},
+	}
+
+	expected := `<tg-emoji emoji-id="5">Hi</tg-emoji> <a href="tg://user?id=7">Bob</a>, ` +
+		"<blockquote expandable>see\nthis</blockquote>"
+	if html := message.HTML(); html != expected {
+		t.Fatalf("unexpected HTML\n%s\n%s", html, expected)
+	}
+
+	expected = "![Hi](tg://emoji?id=5) [Bob](tg://user?id=7), **>see\n>this||"
+	if markdown := message.MarkdownV2(); markdown != expected {
+		t.Fatalf("unexpected MarkdownV2\n%q\n%q", markdown, expected)
+	}
+}
+
+func TestMessageMarkdownV2Pre(t *testing.T) {
+	message := Message{
+		Text:     "code:\nif a > b {}",
+		Entities: []MessageEntity{{Type: "pre", Offset: 6, Length: 11, Language: "go"}},
+	}
+
+	if markdown := message.MarkdownV2(); markdown != "code:\n```go\nif a > b {}```" {
+		t.Fatalf("unexpected MarkdownV2 %q", markdown)
+	}
+	if html := message.HTML(); html != "code:\n<pre><code class=\"language-go\">if a &gt; b {}</code></pre>" {
+		t.Fatalf("unexpected HTML %q", html)
+	}
+}
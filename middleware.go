@@ -0,0 +1,151 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// UpdateMiddleware wraps an UpdateHandlerFunc to add behaviour around update
+// handling.
+type UpdateMiddleware func(next UpdateHandlerFunc) UpdateHandlerFunc
+
+// APIRequest is an outgoing Bot API request.
+type APIRequest struct {
+	// Method is the Bot API method name, such as "sendMessage".
+	Method string
+	// Params are the request parameters.
+	Params Params
+	// Files are the files uploaded with the request. It is nil for requests
+	// sent without a multipart body.
+	Files []RequestFile
+}
+
+// RequestHandlerFunc performs an outgoing Bot API request.
+type RequestHandlerFunc func(ctx context.Context, request APIRequest) (*APIResponse, error)
+
+// RequestMiddleware wraps a RequestHandlerFunc to add behaviour around
+// outgoing requests.
+//
+// Request middleware runs once per call, outside of the rate limiter and
+// automatic retries.
+type RequestMiddleware func(next RequestHandlerFunc) RequestHandlerFunc
+
+// WithRequestMiddleware adds middleware for outgoing requests.
+//
+// The first middleware is the outermost one.
+func WithRequestMiddleware(middlewares ...RequestMiddleware) BotAPIOption {
+	return func(config *botAPIConfig) error {
+		config.requestMiddleware = append(config.requestMiddleware, middlewares...)
+		return nil
+	}
+}
+
+// ChainUpdateMiddleware wraps handler with middlewares.
+//
+// The first middleware is the outermost one.
+func ChainUpdateMiddleware(handler UpdateHandlerFunc, middlewares ...UpdateMiddleware) UpdateHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func chainRequestMiddleware(handler RequestHandlerFunc, middlewares ...RequestMiddleware) RequestHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// PanicError is returned by [RecoverUpdates] when a handler panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("update handler panicked: %v", e.Value)
+}
+
+// RecoverUpdates converts panics in update handlers into a *PanicError.
+func RecoverUpdates() UpdateMiddleware {
+	return func(next UpdateHandlerFunc) UpdateHandlerFunc {
+		return func(ctx context.Context, update Update) (err error) {
+			defer func() {
+				if value := recover(); value != nil {
+					err = &PanicError{
+						Value: value,
+						Stack: debug.Stack(),
+					}
+				}
+			}()
+
+			return next(ctx, update)
+		}
+	}
+}
+
+// LogUpdates logs every handled update with its duration and error.
+// A nil logger uses slog.Default().
+func LogUpdates(logger *slog.Logger) UpdateMiddleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return TimeUpdates(func(ctx context.Context, update Update, duration time.Duration, err error) {
+		if err != nil {
+			logger.ErrorContext(ctx, "telegram update failed",
+				"update_id", update.UpdateID,
+				"update_type", update.Type(),
+				"duration", duration.String(),
+				"error", err,
+			)
+			return
+		}
+
+		logger.DebugContext(ctx, "telegram update handled",
+			"update_id", update.UpdateID,
+			"update_type", update.Type(),
+			"duration", duration.String(),
+		)
+	})
+}
+
+// TimeUpdates calls observe with the duration and result of every handled
+// update.
+func TimeUpdates(observe func(ctx context.Context, update Update, duration time.Duration, err error)) UpdateMiddleware {
+	return func(next UpdateHandlerFunc) UpdateHandlerFunc {
+		return func(ctx context.Context, update Update) error {
+			start := time.Now()
+			err := next(ctx, update)
+			observe(ctx, update, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// AllowUsers only passes updates sent by the given users to the handler.
+// Other updates, including updates without a sender, are dropped.
+func AllowUsers(userIDs ...int64) UpdateMiddleware {
+	allowed := make(map[int64]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		allowed[id] = struct{}{}
+	}
+
+	return func(next UpdateHandlerFunc) UpdateHandlerFunc {
+		return func(ctx context.Context, update Update) error {
+			user := update.SentFrom()
+			if user == nil {
+				return nil
+			}
+			if _, ok := allowed[user.ID]; !ok {
+				return nil
+			}
+
+			return next(ctx, update)
+		}
+	}
+}
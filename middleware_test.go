@@ -0,0 +1,147 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChainUpdateMiddlewareOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) UpdateMiddleware {
+		return func(next UpdateHandlerFunc) UpdateHandlerFunc {
+			return func(ctx context.Context, update Update) error {
+				calls = append(calls, name+":before")
+				err := next(ctx, update)
+				calls = append(calls, name+":after")
+				return err
+			}
+		}
+	}
+
+	handler := ChainUpdateMiddleware(func(ctx context.Context, update Update) error {
+		calls = append(calls, "handler")
+		return nil
+	}, trace("outer"), trace("inner"))
+
+	if err := handler(context.Background(), Update{}); err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+
+	want := "outer:before,inner:before,handler,inner:after,outer:after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("unexpected call order %q, want %q", got, want)
+	}
+}
+
+func TestDispatcherUseWrapsHandlers(t *testing.T) {
+	dispatcher := NewDispatcher()
+	dispatcher.Use(RecoverUpdates(), AllowUsers(1))
+	dispatcher.HandleFallback(func(ctx context.Context, update Update) error {
+		panic("boom")
+	})
+
+	err := dispatcher.Dispatch(context.Background(), Update{Message: &Message{From: &User{ID: 1}}})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("expected recovered panic, got %v", err)
+	}
+
+	if err := dispatcher.Dispatch(context.Background(), Update{Message: &Message{From: &User{ID: 2}}}); err != nil {
+		t.Fatalf("update from disallowed user reached handler: %v", err)
+	}
+	if err := dispatcher.Dispatch(context.Background(), Update{Poll: &Poll{}}); err != nil {
+		t.Fatalf("update without sender reached handler: %v", err)
+	}
+}
+
+func TestLogUpdatesAndTimeUpdates(t *testing.T) {
+	var records []slog.Record
+	handlerErr := errors.New("handler failed")
+
+	var observed time.Duration
+	handler := ChainUpdateMiddleware(func(ctx context.Context, update Update) error {
+		return handlerErr
+	}, LogUpdates(slog.New(recordingSlogHandler{records: &records})), TimeUpdates(func(ctx context.Context, update Update, duration time.Duration, err error) {
+		observed = duration
+		if !errors.Is(err, handlerErr) {
+			t.Fatalf("unexpected observed error: %v", err)
+		}
+	}))
+
+	if err := handler(context.Background(), Update{UpdateID: 7, Message: &Message{}}); !errors.Is(err, handlerErr) {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+	if observed <= 0 {
+		t.Fatalf("duration was not observed")
+	}
+	if len(records) != 1 || records[0].Message != "telegram update failed" || records[0].Level != slog.LevelError {
+		t.Fatalf("unexpected log records: %+v", records)
+	}
+}
+
+func TestRequestMiddlewareWrapsRequests(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			return okAPIResponse(), nil
+		},
+	})
+
+	var seen []APIRequest
+	var results []bool
+	bot.requestMiddleware = []RequestMiddleware{
+		func(next RequestHandlerFunc) RequestHandlerFunc {
+			return func(ctx context.Context, request APIRequest) (*APIResponse, error) {
+				seen = append(seen, request)
+				resp, err := next(ctx, request)
+				results = append(results, err == nil && resp.Ok)
+				return resp, err
+			}
+		},
+	}
+
+	if _, err := bot.Request(NewMessage(123, "hello")); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if _, err := bot.Request(NewPhoto(123, FileBytes{Name: "photo.jpg", Bytes: []byte("image")})); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(seen))
+	}
+	if seen[0].Method != "sendMessage" || seen[0].Params["text"] != "hello" || seen[0].Files != nil {
+		t.Fatalf("unexpected first request: %+v", seen[0])
+	}
+	if seen[1].Method != "sendPhoto" || len(seen[1].Files) != 1 || seen[1].Files[0].Name != "photo" {
+		t.Fatalf("unexpected upload request: %+v", seen[1])
+	}
+	if len(results) != 2 || !results[0] || !results[1] {
+		t.Fatalf("middleware did not observe responses: %v", results)
+	}
+}
+
+func TestRequestMiddlewareCanShortCircuit(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("request reached HTTP client")
+			return nil, nil
+		},
+	})
+	blocked := errors.New("blocked")
+	bot.requestMiddleware = []RequestMiddleware{
+		func(next RequestHandlerFunc) RequestHandlerFunc {
+			return func(ctx context.Context, request APIRequest) (*APIResponse, error) {
+				return nil, blocked
+			}
+		},
+	}
+
+	if _, err := bot.Request(NewMessage(123, "hello")); !errors.Is(err, blocked) {
+		t.Fatalf("expected middleware error, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OffsetStore persists the offset of the next update to receive, which is one
+// higher than the ID of the last processed update.
+type OffsetStore interface {
+	// LoadOffset returns the stored offset, or zero if none was stored.
+	LoadOffset(ctx context.Context) (int, error)
+	// SaveOffset stores the offset.
+	SaveOffset(ctx context.Context, offset int) error
+}
+
+// MemoryOffsetStore keeps the offset in memory.
+type MemoryOffsetStore struct {
+	mu     sync.Mutex
+	offset int
+}
+
+// NewMemoryOffsetStore creates a MemoryOffsetStore starting at offset.
+func NewMemoryOffsetStore(offset int) *MemoryOffsetStore {
+	return &MemoryOffsetStore{offset: offset}
+}
+
+// LoadOffset returns the stored offset.
+func (s *MemoryOffsetStore) LoadOffset(context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.offset, nil
+}
+
+// SaveOffset stores the offset.
+func (s *MemoryOffsetStore) SaveOffset(_ context.Context, offset int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset = offset
+	return nil
+}
+
+// FileOffsetStore keeps the offset in a file.
+//
+// The file is replaced atomically, so a crash while saving leaves either the
+// previous or the new offset.
+type FileOffsetStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileOffsetStore creates a FileOffsetStore using the file at path.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+// LoadOffset reads the offset from the file. A missing file is treated as
+// offset zero.
+func (s *FileOffsetStore) LoadOffset(context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// SaveOffset writes the offset to the file.
+func (s *FileOffsetStore) SaveOffset(_ context.Context, offset int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(strconv.Itoa(offset) + "\n"); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path)
+}
@@ -0,0 +1,57 @@
+package tgbotapi
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryOffsetStore(t *testing.T) {
+	store := NewMemoryOffsetStore(5)
+	ctx := context.Background()
+
+	if offset, err := store.LoadOffset(ctx); err != nil || offset != 5 {
+		t.Fatalf("unexpected initial offset %d (%v)", offset, err)
+	}
+	if err := store.SaveOffset(ctx, 9); err != nil {
+		t.Fatalf("save offset: %v", err)
+	}
+	if offset, err := store.LoadOffset(ctx); err != nil || offset != 9 {
+		t.Fatalf("unexpected saved offset %d (%v)", offset, err)
+	}
+}
+
+func TestFileOffsetStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "offset")
+	store := NewFileOffsetStore(path)
+	ctx := context.Background()
+
+	if offset, err := store.LoadOffset(ctx); err != nil || offset != 0 {
+		t.Fatalf("missing file should load zero, got %d (%v)", offset, err)
+	}
+	if err := store.SaveOffset(ctx, 42); err != nil {
+		t.Fatalf("save offset: %v", err)
+	}
+
+	reopened := NewFileOffsetStore(path)
+	if offset, err := reopened.LoadOffset(ctx); err != nil || offset != 42 {
+		t.Fatalf("unexpected reloaded offset %d (%v)", offset, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+
+	if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := reopened.LoadOffset(ctx); err == nil {
+		t.Fatalf("expected error for corrupt offset file")
+	}
+}
@@ -0,0 +1,725 @@
+package tgbotapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EntityParseError is returned when formatted text can't be parsed. Message
+// mirrors the description Telegram returns after "can't parse entities: ".
+type EntityParseError struct {
+	// Offset is the byte offset in the formatted text the error refers to.
+	Offset  int
+	Message string
+}
+
+func (e *EntityParseError) Error() string {
+	return "can't parse entities: " + e.Message
+}
+
+func newEntityParseError(offset int, format string, args ...any) *EntityParseError {
+	return &EntityParseError{Offset: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseMarkup parses text formatted for parseMode into plain text and
+// entities, as Telegram does for messages sent with that ParseMode. ModeHTML
+// and ModeMarkdownV2 are supported, and text without a parse mode is returned
+// as is.
+//
+// Parse errors are returned as *EntityParseError.
+func ParseMarkup(text, parseMode string) (string, []MessageEntity, error) {
+	switch parseMode {
+	case "":
+		return text, nil, nil
+	case ModeHTML:
+		return ParseHTML(text)
+	case ModeMarkdownV2:
+		return ParseMarkdownV2(text)
+	}
+	return "", nil, fmt.Errorf("unsupported parse mode %q", parseMode)
+}
+
+// markupParser collects the plain text and entities of parsed markup.
+type markupParser struct {
+	text     strings.Builder
+	length   int
+	entities []MessageEntity
+}
+
+func (p *markupParser) write(text string) {
+	p.text.WriteString(text)
+	p.length += UTF16Len(text)
+}
+
+// writeRune writes the rune at the start of text and returns its size.
+func (p *markupParser) writeRune(text string) int {
+	_, size := utf8.DecodeRuneInString(text)
+	p.write(text[:size])
+	return size
+}
+
+// open starts an entity at the current position and returns its index.
+// Entities are stored in the order they are opened, which puts outer entities
+// before the entities nested in them.
+func (p *markupParser) open(entity MessageEntity) int {
+	entity.Offset = p.length
+	p.entities = append(p.entities, entity)
+	return len(p.entities) - 1
+}
+
+// close ends the entity at index at the current position.
+func (p *markupParser) close(index int) *MessageEntity {
+	entity := &p.entities[index]
+	entity.Length = p.length - entity.Offset
+	return entity
+}
+
+// closeLink completes a text_link entity whose text starts at textPos. Links
+// without a URL point to their text, and links to users become text mentions.
+func (p *markupParser) closeLink(entity *MessageEntity, textPos int) {
+	if entity.URL == "" {
+		entity.URL = p.text.String()[textPos:]
+	}
+	if id, ok := strings.CutPrefix(entity.URL, "tg://user?id="); ok {
+		if userID, err := strconv.ParseInt(id, 10, 64); err == nil {
+			entity.Type, entity.URL, entity.User = "text_mention", "", &User{ID: userID}
+		}
+	}
+}
+
+func (p *markupParser) result() (string, []MessageEntity) {
+	entities := make([]MessageEntity, 0, len(p.entities))
+	for _, entity := range p.entities {
+		if entity.Type != "" && entity.Length > 0 {
+			entities = append(entities, entity)
+		}
+	}
+	return p.text.String(), entities
+}
+
+func checkMarkupEncoding(text string) error {
+	if !utf8.ValidString(text) {
+		return newEntityParseError(0, "Strings must be encoded in UTF-8")
+	}
+	return nil
+}
+
+// htmlTagEntities maps the supported HTML tags to entity types.
+var htmlTagEntities = map[string]string{
+	"a":          "text_link",
+	"b":          "bold",
+	"strong":     "bold",
+	"i":          "italic",
+	"em":         "italic",
+	"u":          "underline",
+	"ins":        "underline",
+	"s":          "strikethrough",
+	"strike":     "strikethrough",
+	"del":        "strikethrough",
+	"span":       "spoiler",
+	"tg-spoiler": "spoiler",
+	"code":       "code",
+	"pre":        "pre",
+	"tg-emoji":   "custom_emoji",
+	"tg-time":    "date_time",
+	"blockquote": "blockquote",
+}
+
+type htmlTag struct {
+	name string
+	// offset is the byte offset of the start tag.
+	offset  int
+	index   int
+	textPos int
+}
+
+type htmlParser struct {
+	markupParser
+	src   string
+	stack []htmlTag
+}
+
+// ParseHTML parses text formatted for ModeHTML into plain text and entities.
+func ParseHTML(text string) (string, []MessageEntity, error) {
+	if err := checkMarkupEncoding(text); err != nil {
+		return "", nil, err
+	}
+
+	p := htmlParser{src: text}
+	for i := 0; i < len(text); {
+		var err error
+		switch text[i] {
+		case '&':
+			decoded, size := decodeHTMLEntity(text[i:])
+			if size == 0 {
+				decoded, size = "&", 1
+			}
+			p.write(decoded)
+			i += size
+		case '<':
+			if i+1 < len(text) && text[i+1] == '/' {
+				i, err = p.endTag(i)
+			} else {
+				i, err = p.startTag(i)
+			}
+			if err != nil {
+				return "", nil, err
+			}
+		default:
+			i += p.writeRune(text[i:])
+		}
+	}
+
+	if len(p.stack) > 0 {
+		tag := p.stack[len(p.stack)-1]
+		return "", nil, newEntityParseError(tag.offset, "Can't find end tag corresponding to start tag \"%s\"", tag.name)
+	}
+
+	text, entities := p.result()
+	return text, entities, nil
+}
+
+// startTag parses the start tag at begin and returns the offset after it.
+func (p *htmlParser) startTag(begin int) (int, error) {
+	unclosed := newEntityParseError(begin, "Unclosed start tag at byte offset %d", begin)
+
+	i := p.skipName(begin + 1)
+	name := strings.ToLower(p.src[begin+1 : i])
+	if i == len(p.src) {
+		return 0, unclosed
+	}
+	entityType, ok := htmlTagEntities[name]
+	if !ok {
+		return 0, newEntityParseError(begin, "Unsupported start tag \"%s\" at byte offset %d", name, begin)
+	}
+
+	attributes := make(map[string]string)
+	for {
+		i = p.skipSpaces(i)
+		if i == len(p.src) {
+			return 0, unclosed
+		}
+		if p.src[i] == '>' {
+			i++
+			break
+		}
+
+		start := i
+		for i < len(p.src) && !isMarkupSpace(p.src[i]) && p.src[i] != '=' && p.src[i] != '>' {
+			i++
+		}
+		attribute := strings.ToLower(p.src[start:i])
+		if attribute == "" {
+			return 0, newEntityParseError(begin, "Empty attribute name in the tag \"%s\" at byte offset %d", name, begin)
+		}
+
+		i = p.skipSpaces(i)
+		if i == len(p.src) {
+			return 0, unclosed
+		}
+		if p.src[i] != '=' {
+			if name == "blockquote" && attribute == "expandable" {
+				attributes[attribute] = ""
+				continue
+			}
+			return 0, newEntityParseError(begin, "Expected equal sign in declaration of an attribute of the tag \"%s\" at byte offset %d", name, begin)
+		}
+
+		i = p.skipSpaces(i + 1)
+		if i == len(p.src) {
+			return 0, unclosed
+		}
+		if quote := p.src[i]; quote == '"' || quote == '\'' {
+			end := strings.IndexByte(p.src[i+1:], quote)
+			if end < 0 {
+				return 0, unclosed
+			}
+			attributes[attribute] = decodeHTMLEntities(p.src[i+1 : i+1+end])
+			i += end + 2
+		} else {
+			start := i
+			for i < len(p.src) && isHTMLValueByte(p.src[i]) {
+				i++
+			}
+			if i == start {
+				return 0, newEntityParseError(i, "Unexpected end of name token at byte offset %d", i)
+			}
+			attributes[attribute] = p.src[start:i]
+		}
+	}
+
+	entity := MessageEntity{Type: entityType}
+	switch name {
+	case "a":
+		entity.URL = attributes["href"]
+	case "span":
+		if attributes["class"] != "tg-spoiler" {
+			return 0, newEntityParseError(begin, "Tag \"span\" must have class \"tg-spoiler\" at byte offset %d", begin)
+		}
+	case "code":
+		if language, ok := strings.CutPrefix(attributes["class"], "language-"); ok {
+			entity.Language = language
+		}
+	case "tg-emoji":
+		entity.CustomEmojiID = attributes["emoji-id"]
+		if !isMarkupID(entity.CustomEmojiID) {
+			return 0, newEntityParseError(begin, "Invalid custom emoji identifier specified")
+		}
+	case "tg-time":
+		unixTime, err := strconv.ParseInt(attributes["unix"], 10, 64)
+		if err != nil {
+			return 0, newEntityParseError(begin, "Invalid Unix time specified")
+		}
+		entity.UnixTime, entity.DateTimeFormat = unixTime, attributes["format"]
+	case "blockquote":
+		if _, ok := attributes["expandable"]; ok {
+			entity.Type = "expandable_blockquote"
+		}
+	}
+
+	index := p.open(entity)
+	p.stack = append(p.stack, htmlTag{name: name, offset: begin, index: index, textPos: p.text.Len()})
+
+	return i, nil
+}
+
+// endTag parses the end tag at begin and returns the offset after it.
+func (p *htmlParser) endTag(begin int) (int, error) {
+	i := p.skipName(begin + 2)
+	name := strings.ToLower(p.src[begin+2 : i])
+	i = p.skipSpaces(i)
+	if i == len(p.src) || p.src[i] != '>' {
+		return 0, newEntityParseError(begin, "Unclosed end tag at byte offset %d", begin)
+	}
+	if len(p.stack) == 0 {
+		return 0, newEntityParseError(begin, "Unexpected end tag at byte offset %d", begin)
+	}
+
+	tag := p.stack[len(p.stack)-1]
+	if name != tag.name {
+		return 0, newEntityParseError(begin, "Unmatched end tag at byte offset %d, expected \"</%s>\", found \"</%s>\"", begin, tag.name, name)
+	}
+	p.stack = p.stack[:len(p.stack)-1]
+
+	entity := p.close(tag.index)
+	switch entity.Type {
+	case "text_link":
+		p.closeLink(entity, tag.textPos)
+	case "code":
+		// The language of code is only used by an enclosing pre.
+		if len(p.stack) == 0 || p.stack[len(p.stack)-1].name != "pre" {
+			entity.Language = ""
+		}
+	case "pre":
+		if tag.index+1 < len(p.entities) {
+			code := &p.entities[tag.index+1]
+			if code.Type == "code" && code.Offset == entity.Offset && code.Length == entity.Length {
+				entity.Language = code.Language
+				code.Type = ""
+			}
+		}
+	}
+
+	return i + 1, nil
+}
+
+func (p *htmlParser) skipName(i int) int {
+	for i < len(p.src) && isHTMLNameByte(p.src[i]) {
+		i++
+	}
+	return i
+}
+
+func (p *htmlParser) skipSpaces(i int) int {
+	for i < len(p.src) && isMarkupSpace(p.src[i]) {
+		i++
+	}
+	return i
+}
+
+func isHTMLNameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-'
+}
+
+func isHTMLValueByte(c byte) bool {
+	return isHTMLNameByte(c) || c == '.' || c == '_' || c == ':'
+}
+
+func isMarkupSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isMarkupID(id string) bool {
+	_, err := strconv.ParseInt(id, 10, 64)
+	return err == nil
+}
+
+// decodeHTMLEntity decodes the character reference at the start of text and
+// returns its size, or 0 if text doesn't start with a supported reference.
+func decodeHTMLEntity(text string) (string, int) {
+	end := strings.IndexByte(text[:min(len(text), 12)], ';')
+	if end < 2 {
+		return "", 0
+	}
+
+	name := text[1:end]
+	switch name {
+	case "lt":
+		return "<", end + 1
+	case "gt":
+		return ">", end + 1
+	case "amp":
+		return "&", end + 1
+	case "quot":
+		return "\"", end + 1
+	}
+
+	if name[0] != '#' || len(name) < 2 {
+		return "", 0
+	}
+	var code uint64
+	var err error
+	if name[1] == 'x' || name[1] == 'X' {
+		code, err = strconv.ParseUint(name[2:], 16, 32)
+	} else {
+		code, err = strconv.ParseUint(name[1:], 10, 32)
+	}
+	if err != nil || code == 0 || !utf8.ValidRune(rune(code)) {
+		return "", 0
+	}
+	return string(rune(code)), end + 1
+}
+
+func decodeHTMLEntities(text string) string {
+	if !strings.Contains(text, "&") {
+		return text
+	}
+
+	var decoded strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] == '&' {
+			if value, size := decodeHTMLEntity(text[i:]); size > 0 {
+				decoded.WriteString(value)
+				i += size
+				continue
+			}
+		}
+		decoded.WriteByte(text[i])
+		i++
+	}
+	return decoded.String()
+}
+
+// markdownV2Reserved are the characters which must be escaped in MarkdownV2
+// text.
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!"
+
+// markdownV2EntityNames are the names Telegram uses for entities in errors.
+var markdownV2EntityNames = map[string]string{
+	"bold":          "Bold",
+	"italic":        "Italic",
+	"underline":     "Underline",
+	"strikethrough": "Strikethrough",
+	"spoiler":       "Spoiler",
+	"text_link":     "TextUrl",
+	"custom_emoji":  "CustomEmoji",
+	"code":          "Code",
+	"pre":           "Pre",
+}
+
+type markdownV2Entity struct {
+	// offset is the byte offset of the entity start.
+	offset  int
+	index   int
+	textPos int
+}
+
+type markdownV2Parser struct {
+	markupParser
+	src   string
+	stack []markdownV2Entity
+	// quote is the index of the open block quotation, or -1.
+	quote      int
+	quoteDepth int
+}
+
+// ParseMarkdownV2 parses text formatted for ModeMarkdownV2 into plain text and
+// entities.
+func ParseMarkdownV2(text string) (string, []MessageEntity, error) {
+	if err := checkMarkupEncoding(text); err != nil {
+		return "", nil, err
+	}
+
+	p := markdownV2Parser{src: text, quote: -1}
+	lineStart := true
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		if lineStart {
+			lineStart = false
+			if p.quote >= 0 && c == '>' {
+				i++
+				continue
+			}
+			if p.quote < 0 && !p.inCode() {
+				if c == '>' {
+					p.openQuote("blockquote")
+					i++
+					continue
+				}
+				if strings.HasPrefix(text[i:], "**>") {
+					p.openQuote("expandable_blockquote")
+					i += 3
+					continue
+				}
+			}
+		}
+
+		if c == '\\' && i+1 < len(text) && text[i+1] > 0 && text[i+1] <= 126 {
+			p.write(text[i+1 : i+2])
+			i += 2
+			continue
+		}
+
+		// Carriage returns are ignored, which allows separating markers such
+		// as "_" and "__".
+		if c == '\r' && !p.inCode() {
+			i++
+			continue
+		}
+
+		if c == '\n' {
+			if p.quote >= 0 && !strings.HasPrefix(text[i+1:], ">") {
+				if err := p.closeQuote(); err != nil {
+					return "", nil, err
+				}
+			}
+			p.write("\n")
+			lineStart = true
+			i++
+			continue
+		}
+
+		if !strings.ContainsRune(markdownV2Reserved, rune(c)) || p.inCode() && c != '`' {
+			i += p.writeRune(text[i:])
+			continue
+		}
+
+		// "||" at the end of a quoted line makes the quotation expandable.
+		if p.quote >= 0 && strings.HasPrefix(text[i:], "||") && p.top() != "spoiler" &&
+			(i+2 == len(text) || text[i+2] == '\n') {
+			p.entities[p.quote].Type = "expandable_blockquote"
+			if err := p.closeQuote(); err != nil {
+				return "", nil, err
+			}
+			i += 2
+			continue
+		}
+
+		var err error
+		if p.isEnd(i) {
+			i, err = p.closeEntity(i)
+		} else {
+			i, err = p.openEntity(i)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(p.stack) > 0 {
+		return "", nil, p.unclosedError()
+	}
+	if p.quote >= 0 {
+		p.close(p.quote)
+	}
+
+	text, entities := p.result()
+	return text, entities, nil
+}
+
+// top returns the type of the innermost open entity.
+func (p *markdownV2Parser) top() string {
+	if len(p.stack) == 0 {
+		return ""
+	}
+	return p.entities[p.stack[len(p.stack)-1].index].Type
+}
+
+func (p *markdownV2Parser) inCode() bool {
+	top := p.top()
+	return top == "code" || top == "pre"
+}
+
+func (p *markdownV2Parser) openQuote(entityType string) {
+	p.quote = p.open(MessageEntity{Type: entityType})
+	p.quoteDepth = len(p.stack)
+}
+
+// closeQuote ends the open block quotation, which must not contain open
+// entities.
+func (p *markdownV2Parser) closeQuote() error {
+	if len(p.stack) > p.quoteDepth {
+		return p.unclosedError()
+	}
+	p.close(p.quote)
+	p.quote = -1
+	return nil
+}
+
+func (p *markdownV2Parser) unclosedError() error {
+	entity := p.stack[len(p.stack)-1]
+	name := markdownV2EntityNames[p.entities[entity.index].Type]
+	return newEntityParseError(entity.offset, "Can't find end of %s entity at byte offset %d", name, entity.offset)
+}
+
+// isEnd reports whether the character at i ends the innermost open entity.
+func (p *markdownV2Parser) isEnd(i int) bool {
+	text := p.src[i:]
+	switch p.top() {
+	case "bold":
+		return text[0] == '*'
+	case "italic":
+		return text[0] == '_' && !strings.HasPrefix(text, "__")
+	case "underline":
+		return strings.HasPrefix(text, "__")
+	case "strikethrough":
+		return text[0] == '~'
+	case "spoiler":
+		return strings.HasPrefix(text, "||")
+	case "code":
+		return text[0] == '`'
+	case "pre":
+		return strings.HasPrefix(text, "```")
+	case "text_link", "custom_emoji":
+		return text[0] == ']'
+	}
+	return false
+}
+
+// openEntity opens the entity starting at begin and returns the offset after
+// its start marker.
+func (p *markdownV2Parser) openEntity(begin int) (int, error) {
+	text := p.src[begin:]
+	i := begin + 1
+
+	var entityType, language string
+	switch {
+	case strings.HasPrefix(text, "__"):
+		entityType, i = "underline", begin+2
+	case text[0] == '_':
+		entityType = "italic"
+	case text[0] == '*':
+		entityType = "bold"
+	case text[0] == '~':
+		entityType = "strikethrough"
+	case strings.HasPrefix(text, "||"):
+		entityType, i = "spoiler", begin+2
+	case text[0] == '[':
+		entityType = "text_link"
+	case strings.HasPrefix(text, "!["):
+		entityType, i = "custom_emoji", begin+2
+	case strings.HasPrefix(text, "```"):
+		entityType, i = "pre", begin+3
+		end := i
+		for end < len(p.src) && !isMarkupSpace(p.src[end]) && p.src[end] != '`' {
+			end++
+		}
+		if end > i && end < len(p.src) && p.src[end] != '`' {
+			language, i = p.src[i:end], end
+		}
+		// A new line after the start of the block is not part of it.
+		if strings.HasPrefix(p.src[i:], "\r\n") || strings.HasPrefix(p.src[i:], "\n\r") {
+			i += 2
+		} else if i < len(p.src) && (p.src[i] == '\n' || p.src[i] == '\r') {
+			i++
+		}
+	case text[0] == '`' && !p.inCode():
+		entityType = "code"
+	default:
+		return 0, newEntityParseError(begin, "Character '%c' is reserved and must be escaped with the preceding '\\'", text[0])
+	}
+
+	index := p.open(MessageEntity{Type: entityType, Language: language})
+	p.stack = append(p.stack, markdownV2Entity{offset: begin, index: index, textPos: p.text.Len()})
+
+	return i, nil
+}
+
+// closeEntity closes the innermost entity ending at begin and returns the
+// offset after its end marker.
+func (p *markdownV2Parser) closeEntity(begin int) (int, error) {
+	opened := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	entity := p.close(opened.index)
+
+	switch entity.Type {
+	case "underline", "spoiler":
+		return begin + 2, nil
+	case "pre":
+		return begin + 3, nil
+	case "text_link", "custom_emoji":
+	default:
+		return begin + 1, nil
+	}
+
+	i := begin + 1
+	var link string
+	if i < len(p.src) && p.src[i] == '(' {
+		start := i + 1
+		var builder strings.Builder
+		for i = start; i < len(p.src) && p.src[i] != ')'; i++ {
+			if p.src[i] == '\\' && i+1 < len(p.src) && p.src[i+1] > 0 && p.src[i+1] <= 126 {
+				i++
+			}
+			builder.WriteByte(p.src[i])
+		}
+		if i == len(p.src) {
+			return 0, newEntityParseError(start, "Can't find end of a URL at byte offset %d", start)
+		}
+		link = builder.String()
+		i++
+	}
+
+	if entity.Type == "text_link" {
+		entity.URL = link
+		p.closeLink(entity, opened.textPos)
+		return i, nil
+	}
+
+	if err := setMarkdownV2EmojiURL(entity, link); err != nil {
+		err.Offset = opened.offset
+		return 0, err
+	}
+	return i, nil
+}
+
+// setMarkdownV2EmojiURL sets the custom emoji or date and time of an entity
+// written as "![text](url)".
+func setMarkdownV2EmojiURL(entity *MessageEntity, link string) *EntityParseError {
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "tg" {
+		return newEntityParseError(0, "Custom emoji entity must contain a tg://emoji URL")
+	}
+
+	query := u.Query()
+	switch u.Host {
+	case "emoji":
+		entity.CustomEmojiID = query.Get("id")
+		if !isMarkupID(entity.CustomEmojiID) {
+			return newEntityParseError(0, "Invalid custom emoji identifier specified")
+		}
+	case "time":
+		unixTime, err := strconv.ParseInt(query.Get("unix"), 10, 64)
+		if err != nil {
+			return newEntityParseError(0, "Invalid Unix time specified")
+		}
+		entity.Type, entity.UnixTime, entity.DateTimeFormat = "date_time", unixTime, query.Get("format")
+	default:
+		return newEntityParseError(0, "Custom emoji entity must contain a tg://emoji URL")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package tgbotapi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	text, entities, err := ParseHTML(`<b>bold <I>both</I></b> &lt;&amp;&#x1F44B;&gt; ` +
+		`<a href="https://example.com/?a=1&amp;b=2">link</a> <a href='tg://user?id=42'>you</a> ` +
+		`<span class="tg-spoiler">x</span><pre><code class="language-go">fmt</code></pre>` +
+		`<code class="language-go">y</code><blockquote expandable>q</blockquote><tg-emoji emoji-id="5">👍</tg-emoji>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text != "bold both <&👋> link you xfmtyq👍" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	expected := []MessageEntity{
+		{Type: "bold", Offset: 0, Length: 9},
+		{Type: "italic", Offset: 5, Length: 4},
+		{Type: "text_link", Offset: 16, Length: 4, URL: "https://example.com/?a=1&b=2"},
+		{Type: "text_mention", Offset: 21, Length: 3, User: &User{ID: 42}},
+		{Type: "spoiler", Offset: 25, Length: 1},
+		{Type: "pre", Offset: 26, Length: 3, Language: "go"},
+		{Type: "code", Offset: 29, Length: 1},
+		{Type: "expandable_blockquote", Offset: 30, Length: 1},
+		{Type: "custom_emoji", Offset: 31, Length: 2, CustomEmojiID: "5"},
+	}
+	if !reflect.DeepEqual(entities, expected) {
+		t.Fatalf("unexpected entities\n%+v\n%+v", entities, expected)
+	}
+}
+
+func TestParseHTMLErrors(t *testing.T) {
+	tests := []struct {
+		text    string
+		message string
+		offset  int
+	}{
+		{"a < b", `Unsupported start tag "" at byte offset 2`, 2},
+		{"<foo>x</foo>", `Unsupported start tag "foo" at byte offset 0`, 0},
+		{"x<b", "Unclosed start tag at byte offset 1", 1},
+		{"<b>x</i>", `Unmatched end tag at byte offset 4, expected "</b>", found "</i>"`, 4},
+		{"x</b>", "Unexpected end tag at byte offset 1", 1},
+		{"<b>x</b", "Unclosed end tag at byte offset 4", 4},
+		{"<b><i>x</i>", `Can't find end tag corresponding to start tag "b"`, 0},
+		{`<a href>x</a>`, `Expected equal sign in declaration of an attribute of the tag "a" at byte offset 0`, 0},
+		{`<span>x</span>`, `Tag "span" must have class "tg-spoiler" at byte offset 0`, 0},
+		{`<tg-emoji emoji-id="x">y</tg-emoji>`, "Invalid custom emoji identifier specified", 0},
+	}
+
+	for _, test := range tests {
+		_, _, err := ParseHTML(test.text)
+		var parseErr *EntityParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("%q: unexpected error %v", test.text, err)
+		}
+		if parseErr.Message != test.message || parseErr.Offset != test.offset {
+			t.Errorf("%q: unexpected error %q at %d", test.text, parseErr.Message, parseErr.Offset)
+		}
+	}
+}
+
+func TestParseMarkdownV2(t *testing.T) {
+	text, entities, err := ParseMarkdownV2("*bold _both_*\\. ___iu_\r__ ~s~ ||sp|| [link](https://example.com/\\(x\\)) " +
+		"[you](tg://user?id=42) `a\\`b` ```go\nfmt```![👍](tg://emoji?id=5)\n>q1\n>q2\n**>e||")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text != "bold both. iu s sp link you a`b fmt👍\nq1\nq2\ne" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	expected := []MessageEntity{
+		{Type: "bold", Offset: 0, Length: 9},
+		{Type: "italic", Offset: 5, Length: 4},
+		{Type: "underline", Offset: 11, Length: 2},
+		{Type: "italic", Offset: 11, Length: 2},
+		{Type: "strikethrough", Offset: 14, Length: 1},
+		{Type: "spoiler", Offset: 16, Length: 2},
+		{Type: "text_link", Offset: 19, Length: 4, URL: "https://example.com/(x)"},
+		{Type: "text_mention", Offset: 24, Length: 3, User: &User{ID: 42}},
+		{Type: "code", Offset: 28, Length: 3},
+		{Type: "pre", Offset: 32, Length: 3, Language: "go"},
+		{Type: "custom_emoji", Offset: 35, Length: 2, CustomEmojiID: "5"},
+		{Type: "blockquote", Offset: 38, Length: 5},
+		{Type: "expandable_blockquote", Offset: 44, Length: 1},
+	}
+	if !reflect.DeepEqual(entities, expected) {
+		t.Fatalf("unexpected entities\n%+v\n%+v", entities, expected)
+	}
+}
+
+func TestParseMarkdownV2Errors(t *testing.T) {
+	tests := []struct {
+		text    string
+		message string
+		offset  int
+	}{
+		{"1.5", `Character '.' is reserved and must be escaped with the preceding '\'`, 1},
+		{"a *b", "Can't find end of Bold entity at byte offset 2", 2},
+		{"_a *b_", "Can't find end of Italic entity at byte offset 5", 5},
+		{"[a](b", "Can't find end of a URL at byte offset 4", 4},
+		{"![a](https://example.com)", "Custom emoji entity must contain a tg://emoji URL", 0},
+		{">*a\nb*", "Can't find end of Bold entity at byte offset 1", 1},
+		{"```\na`b```", "Character '`' is reserved and must be escaped with the preceding '\\'", 5},
+	}
+
+	for _, test := range tests {
+		_, _, err := ParseMarkdownV2(test.text)
+		var parseErr *EntityParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("%q: unexpected error %v", test.text, err)
+		}
+		if parseErr.Message != test.message || parseErr.Offset != test.offset {
+			t.Errorf("%q: unexpected error %q at %d", test.text, parseErr.Message, parseErr.Offset)
+		}
+	}
+}
+
+func TestParseMarkupRoundTrip(t *testing.T) {
+	var text TextBuilder
+	text.Text("1+1=2 <ok> & ").
+		Nest(MessageEntity{Type: "bold"}, func(b *TextBuilder) {
+			b.Text("bold ").Italic("both_")
+		}).
+		Text(" ").
+		Nest(MessageEntity{Type: "underline"}, func(b *TextBuilder) {
+			b.Italic("iu")
+		}).
+		Link("link", "https://example.com/(x)?a=1&b=2").
+		Mention("you", 42).
+		Pre("if a > `b` {}", "go").
+		Code("a\\b").
+		CustomEmoji("👍", "5").
+		DateTime("now", 1700000000, "wDT").
+		Text("\n").
+		Blockquote("line 1\nline 2").
+		Text("\n").
+		ExpandableBlockquote("more").
+		Text("\n").
+		Spoiler("!")
+
+	for _, mode := range []string{ModeHTML, ModeMarkdownV2} {
+		formatted := text.HTML()
+		if mode == ModeMarkdownV2 {
+			formatted = text.MarkdownV2()
+		}
+
+		parsed, entities, err := ParseMarkup(formatted, mode)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", mode, err, formatted)
+		}
+		if parsed != text.String() {
+			t.Errorf("%s: unexpected text %q", mode, parsed)
+		}
+		if !reflect.DeepEqual(entities, text.Entities()) {
+			t.Errorf("%s: unexpected entities\n%+v\n%+v", mode, entities, text.Entities())
+		}
+	}
+}
+
+func TestParseMarkupUnsupportedMode(t *testing.T) {
+	if text, entities, err := ParseMarkup("*a*", ""); err != nil || text != "*a*" || entities != nil {
+		t.Fatalf("unexpected result %q %v %v", text, entities, err)
+	}
+	if _, _, err := ParseMarkup("*a*", ModeMarkdown); err == nil {
+		t.Fatal("expected an error for legacy Markdown")
+	}
+}
@@ -1,5 +1,19 @@
 package tgbotapi
 
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 // PassportRequestInfoConfig allows you to request passport info
 type PassportRequestInfoConfig struct {
 	BotID     int            `json:"bot_id"`
@@ -357,4 +371,221 @@ type (
 		DocumentNumber string `json:"document_no"`
 		ExpiryDate     string `json:"expiry_date"`
 	}
+
+	// ResidentialAddress https://core.telegram.org/passport#residentialaddress
+	ResidentialAddress struct {
+		StreetLine1 string `json:"street_line1"`
+		StreetLine2 string `json:"street_line2"`
+		City        string `json:"city"`
+		State       string `json:"state"`
+		CountryCode string `json:"country_code"`
+		PostCode    string `json:"post_code"`
+	}
 )
+
+// DecryptedPassportElement is an EncryptedPassportElement with its data
+// decrypted.
+type DecryptedPassportElement struct {
+	// Element is the encrypted element.
+	Element EncryptedPassportElement
+	// Credentials are used to decrypt the files of the element with
+	// DecryptPassportFile. Nil for "phone_number" and "email" elements.
+	Credentials *SecureValue
+	// PersonalDetails is set for "personal_details" elements.
+	PersonalDetails *PersonalDetails
+	// IDDocument is set for "passport", "driver_license", "identity_card" and
+	// "internal_passport" elements.
+	IDDocument *IDDocumentData
+	// Address is set for "address" elements.
+	Address *ResidentialAddress
+}
+
+// DecryptPassportCredentials decrypts credentials using the private key of the
+// bot. The secret is decrypted with RSA-OAEP and the data is verified against
+// its hash.
+//
+// The returned Nonce should be compared with the nonce of the passport request.
+func DecryptPassportCredentials(key *rsa.PrivateKey, credentials *EncryptedCredentials) (*Credentials, error) {
+	if credentials == nil {
+		return nil, errors.New("passport credentials are missing")
+	}
+
+	encryptedSecret, err := base64.StdEncoding.DecodeString(credentials.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("decode credentials secret: %w", err)
+	}
+	secret, err := rsa.DecryptOAEP(sha1.New(), nil, key, encryptedSecret, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt credentials secret: %w", err)
+	}
+
+	data, err := decryptPassportBase64(credentials.Data, credentials.Hash, secret)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt credentials: %w", err)
+	}
+
+	var decrypted Credentials
+	if err := json.Unmarshal(data, &decrypted); err != nil {
+		return nil, fmt.Errorf("decode credentials: %w", err)
+	}
+
+	return &decrypted, nil
+}
+
+// DecryptPassportData decrypts the credentials and every element of data
+// using the private key of the bot.
+func DecryptPassportData(key *rsa.PrivateKey, data *PassportData) (*Credentials, []DecryptedPassportElement, error) {
+	if data == nil {
+		return nil, nil, errors.New("passport data is missing")
+	}
+
+	credentials, err := DecryptPassportCredentials(key, data.Credentials)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	elements := make([]DecryptedPassportElement, 0, len(data.Data))
+	for _, element := range data.Data {
+		decrypted, err := DecryptPassportElement(element, credentials)
+		if err != nil {
+			return nil, nil, err
+		}
+		elements = append(elements, *decrypted)
+	}
+
+	return credentials, elements, nil
+}
+
+// DecryptPassportElement decrypts the data of element using the decrypted
+// credentials.
+func DecryptPassportElement(element EncryptedPassportElement, credentials *Credentials) (*DecryptedPassportElement, error) {
+	decrypted := &DecryptedPassportElement{Element: element}
+
+	switch element.Type {
+	case "phone_number", "email":
+		return decrypted, nil
+	}
+
+	if credentials == nil {
+		return nil, errors.New("passport credentials are missing")
+	}
+	value := credentials.Data[element.Type]
+	if value == nil {
+		return nil, fmt.Errorf("no credentials for passport element %q", element.Type)
+	}
+	decrypted.Credentials = value
+
+	var target any
+	switch element.Type {
+	case "personal_details":
+		decrypted.PersonalDetails = &PersonalDetails{}
+		target = decrypted.PersonalDetails
+	case "passport", "driver_license", "identity_card", "internal_passport":
+		decrypted.IDDocument = &IDDocumentData{}
+		target = decrypted.IDDocument
+	case "address":
+		decrypted.Address = &ResidentialAddress{}
+		target = decrypted.Address
+	default:
+		return decrypted, nil
+	}
+
+	if err := DecryptPassportElementData(element, value.Data, target); err != nil {
+		return nil, err
+	}
+
+	return decrypted, nil
+}
+
+// DecryptPassportElementData decrypts the data of element and decodes it into
+// v. The hash of the data must match the hash in credentials.
+func DecryptPassportElementData(element EncryptedPassportElement, credentials *DataCredentials, v any) error {
+	if credentials == nil {
+		return fmt.Errorf("no data credentials for passport element %q", element.Type)
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(credentials.Secret)
+	if err != nil {
+		return fmt.Errorf("decode %s data secret: %w", element.Type, err)
+	}
+
+	data, err := decryptPassportBase64(element.Data, credentials.DataHash, secret)
+	if err != nil {
+		return fmt.Errorf("decrypt %s data: %w", element.Type, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s data: %w", element.Type, err)
+	}
+	return nil
+}
+
+// DecryptPassportFile decrypts the contents of a downloaded PassportFile. The
+// hash of the file must match the hash in credentials.
+func DecryptPassportFile(data []byte, credentials *FileCredentials) ([]byte, error) {
+	if credentials == nil {
+		return nil, errors.New("passport file credentials are missing")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(credentials.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("decode file secret: %w", err)
+	}
+	hash, err := base64.StdEncoding.DecodeString(credentials.FileHash)
+	if err != nil {
+		return nil, fmt.Errorf("decode file hash: %w", err)
+	}
+
+	file, err := decryptPassportData(data, hash, secret)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt file: %w", err)
+	}
+	return file, nil
+}
+
+func decryptPassportBase64(data, hash string, secret []byte) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	decodedHash, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptPassportData(encrypted, decodedHash, secret)
+}
+
+// decryptPassportData decrypts data with AES-256-CBC using the key and IV
+// derived from SHA-512(secret + hash), verifies that the SHA-256 hash of the
+// result matches and strips the random padding.
+func decryptPassportData(data, hash, secret []byte) ([]byte, error) {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted data is not a multiple of the block size")
+	}
+
+	digest := sha512.New()
+	digest.Write(secret)
+	digest.Write(hash)
+	secretHash := digest.Sum(nil)
+
+	block, err := aes.NewCipher(secretHash[:32])
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, secretHash[32:48]).CryptBlocks(decrypted, data)
+
+	sum := sha256.Sum256(decrypted)
+	if subtle.ConstantTimeCompare(sum[:], hash) != 1 {
+		return nil, errors.New("data hash mismatch")
+	}
+
+	padding := int(decrypted[0])
+	if padding < 32 || padding > len(decrypted) {
+		return nil, errors.New("invalid data padding")
+	}
+
+	return decrypted[padding:], nil
+}
@@ -0,0 +1,186 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// encryptPassportData encrypts data the way Telegram Passport does and returns
+// the encrypted data, its hash and the secret.
+func encryptPassportData(t *testing.T, data []byte) (encrypted, hash, secret []byte) {
+	t.Helper()
+
+	padding := 32 + (16-(len(data)+32)%16)%16
+	padded := make([]byte, padding+len(data))
+	if _, err := rand.Read(padded[:padding]); err != nil {
+		t.Fatal(err)
+	}
+	padded[0] = byte(padding)
+	copy(padded[padding:], data)
+
+	sum := sha256.Sum256(padded)
+	hash = sum[:]
+
+	secret = make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha512.Sum512(append(append([]byte{}, secret...), hash...))
+	block, err := aes.NewCipher(digest[:32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted = make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, digest[32:48]).CryptBlocks(encrypted, padded)
+
+	return encrypted, hash, secret
+}
+
+func encryptPassportJSON(t *testing.T, v any) (data string, credentials DataCredentials) {
+	t.Helper()
+
+	payload, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, hash, secret := encryptPassportData(t, payload)
+
+	return base64.StdEncoding.EncodeToString(encrypted), DataCredentials{
+		DataHash: base64.StdEncoding.EncodeToString(hash),
+		Secret:   base64.StdEncoding.EncodeToString(secret),
+	}
+}
+
+func TestDecryptPassportData(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := PersonalDetails{FirstName: "Ada", LastName: "Lovelace", BirthDate: "10.12.1815"}
+	detailsData, detailsCredentials := encryptPassportJSON(t, details)
+
+	document := IDDocumentData{DocumentNumber: "123456", ExpiryDate: "01.01.2030"}
+	documentData, documentCredentials := encryptPassportJSON(t, document)
+
+	scan := []byte("\xff\xd8 front side scan")
+	encryptedScan, scanHash, scanSecret := encryptPassportData(t, scan)
+	scanCredentials := &FileCredentials{
+		FileHash: base64.StdEncoding.EncodeToString(scanHash),
+		Secret:   base64.StdEncoding.EncodeToString(scanSecret),
+	}
+
+	credentials := Credentials{
+		Data: SecureData{
+			"personal_details": {Data: &detailsCredentials},
+			"passport":         {Data: &documentCredentials, FrontSide: scanCredentials},
+		},
+		Nonce: "nonce",
+	}
+	credentialsJSON, err := json.Marshal(credentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedCredentials, credentialsHash, credentialsSecret := encryptPassportData(t, credentialsJSON)
+	encryptedSecret, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &key.PublicKey, credentialsSecret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passportData := &PassportData{
+		Data: []EncryptedPassportElement{
+			{Type: "personal_details", Data: detailsData},
+			{Type: "passport", Data: documentData, FrontSide: &PassportFile{FileID: "front"}},
+			{Type: "email", Email: "ada@example.com"},
+		},
+		Credentials: &EncryptedCredentials{
+			Data:   base64.StdEncoding.EncodeToString(encryptedCredentials),
+			Hash:   base64.StdEncoding.EncodeToString(credentialsHash),
+			Secret: base64.StdEncoding.EncodeToString(encryptedSecret),
+		},
+	}
+
+	decryptedCredentials, elements, err := DecryptPassportData(key, passportData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decryptedCredentials.Nonce != "nonce" {
+		t.Fatalf("unexpected nonce %q", decryptedCredentials.Nonce)
+	}
+	if len(elements) != 3 {
+		t.Fatalf("expected 3 elements, got %d", len(elements))
+	}
+	if elements[0].PersonalDetails == nil || *elements[0].PersonalDetails != details {
+		t.Fatalf("unexpected personal details: %#v", elements[0].PersonalDetails)
+	}
+	if elements[1].IDDocument == nil || *elements[1].IDDocument != document {
+		t.Fatalf("unexpected document: %#v", elements[1].IDDocument)
+	}
+	if elements[2].Credentials != nil || elements[2].Element.Email != "ada@example.com" {
+		t.Fatalf("unexpected email element: %#v", elements[2])
+	}
+
+	file, err := DecryptPassportFile(encryptedScan, elements[1].Credentials.FrontSide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(file, scan) {
+		t.Fatalf("unexpected file contents %q", file)
+	}
+}
+
+func TestDecryptPassportDataMissing(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data *PassportData
+		want string
+	}{
+		{name: "nil data", data: nil, want: "passport data is missing"},
+		{name: "nil credentials", data: &PassportData{}, want: "passport credentials are missing"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := DecryptPassportData(key, test.data)
+			if err == nil || err.Error() != test.want {
+				t.Fatalf("expected %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestDecryptPassportFileHashMismatch(t *testing.T) {
+	encrypted, hash, secret := encryptPassportData(t, []byte("scan"))
+	encrypted[len(encrypted)-1] ^= 1
+
+	_, err := DecryptPassportFile(encrypted, &FileCredentials{
+		FileHash: base64.StdEncoding.EncodeToString(hash),
+		Secret:   base64.StdEncoding.EncodeToString(secret),
+	})
+	if err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Fatalf("expected hash mismatch, got %v", err)
+	}
+}
+
+func TestDecryptPassportElementMissingCredentials(t *testing.T) {
+	_, err := DecryptPassportElement(EncryptedPassportElement{Type: "address", Data: "AAAA"}, &Credentials{})
+	if err == nil {
+		t.Fatal("expected error for missing credentials")
+	}
+}
@@ -0,0 +1,336 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// PollerConfig configures a Poller started by [BotAPI.StartPolling].
+type PollerConfig struct {
+	// Buffer is the capacity of the updates channel.
+	// Defaults to the buffer of the bot.
+	Buffer int
+	// MinBackoff is the delay after the first failed request.
+	// Defaults to one second.
+	MinBackoff time.Duration
+	// MaxBackoff limits the delay between failed requests.
+	// Defaults to one minute.
+	//
+	// Delays are randomized between half and all of their value, unless
+	// MinBackoff equals MaxBackoff, which makes every delay the same.
+	MaxBackoff time.Duration
+	// OnError is called with every failed getUpdates request before the
+	// poller waits to try again. If nil, errors are logged with the logger of
+	// the bot.
+	OnError func(ctx context.Context, err error)
+	// OffsetStore persists the offset of the next update. A stored offset is
+	// loaded when the poller starts and takes precedence over
+	// UpdateConfig.Offset.
+	OffsetStore OffsetStore
+	// Acknowledge enables acknowledgment mode. Each batch of updates must be
+	// confirmed with [Poller.Ack] before the next batch is requested, and
+	// the stored offset only advances past acknowledged updates, so a
+	// restarted poller receives every update that was not processed.
+	Acknowledge bool
+}
+
+// Poller receives updates using long polling until it is stopped.
+type Poller struct {
+	bot     *BotAPI
+	config  UpdateConfig
+	options PollerConfig
+
+	updates chan Update
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	savedOffset int
+
+	ackMu   sync.Mutex
+	pending []int
+	acked   map[int]bool
+	acks    chan struct{}
+}
+
+// StartPolling starts receiving updates in a new goroutine.
+//
+// The poller stops when ctx is done or [Poller.Stop] is called. Failed
+// requests are retried with exponential backoff and jitter.
+func (bot *BotAPI) StartPolling(ctx context.Context, config UpdateConfig, options PollerConfig) *Poller {
+	if options.Buffer <= 0 {
+		options.Buffer = bot.Buffer
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = time.Second
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = max(time.Minute, options.MinBackoff)
+	}
+	options.MaxBackoff = max(options.MaxBackoff, options.MinBackoff)
+
+	ctx, cancel := context.WithCancel(ctx)
+	poller := &Poller{
+		bot:     bot,
+		config:  config,
+		options: options,
+		updates: make(chan Update, options.Buffer),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		acked:   make(map[int]bool),
+		acks:    make(chan struct{}, 1),
+	}
+
+	go poller.run(ctx)
+
+	return poller
+}
+
+// Updates returns the channel of received updates.
+// It is closed after the poller has stopped.
+func (p *Poller) Updates() UpdatesChannel {
+	return p.updates
+}
+
+// Stop stops the poller. It does not wait for the poller to exit.
+func (p *Poller) Stop() {
+	p.cancel()
+}
+
+// Done returns a channel closed once the poller has fully exited.
+func (p *Poller) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the poller has fully exited.
+func (p *Poller) Wait() {
+	<-p.done
+}
+
+// Ack marks an update as processed when the poller runs in acknowledgment
+// mode and commits the offset past all acknowledged updates of the current
+// batch. It returns the error of the offset store, if any.
+//
+// Ack does nothing if acknowledgment mode is disabled.
+func (p *Poller) Ack(ctx context.Context, updateID int) error {
+	if !p.options.Acknowledge {
+		return nil
+	}
+
+	p.ackMu.Lock()
+	defer p.ackMu.Unlock()
+
+	known := false
+	for _, id := range p.pending {
+		if id == updateID {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("update %d is not awaiting acknowledgment", updateID)
+	}
+	p.acked[updateID] = true
+
+	offset := 0
+	for len(p.pending) > 0 && p.acked[p.pending[0]] {
+		offset = p.pending[0] + 1
+		delete(p.acked, p.pending[0])
+		p.pending = p.pending[1:]
+	}
+
+	select {
+	case p.acks <- struct{}{}:
+	default:
+	}
+
+	if offset == 0 || p.options.OffsetStore == nil {
+		return nil
+	}
+	return p.options.OffsetStore.SaveOffset(ctx, offset)
+}
+
+// AckUpdates returns middleware acknowledging every update once the handler
+// has returned, whether or not it failed.
+func (p *Poller) AckUpdates() UpdateMiddleware {
+	return func(next UpdateHandlerFunc) UpdateHandlerFunc {
+		return func(ctx context.Context, update Update) error {
+			err := next(ctx, update)
+			if ackErr := p.Ack(ctx, update.UpdateID); ackErr != nil && err == nil {
+				return ackErr
+			}
+			return err
+		}
+	}
+}
+
+func (p *Poller) run(ctx context.Context) {
+	defer close(p.done)
+	defer close(p.updates)
+
+	failures := 0
+	retry := func(err error) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		failures++
+		delay := p.backoff(failures)
+		p.reportError(ctx, err, delay)
+		return sleepContext(ctx, delay) == nil
+	}
+
+	if !p.loadOffset(ctx, retry) {
+		return
+	}
+	failures = 0
+
+	for ctx.Err() == nil {
+		updates, err := p.bot.GetUpdatesWithContext(ctx, p.config)
+		if err != nil {
+			if !retry(err) {
+				return
+			}
+			continue
+		}
+		failures = 0
+
+		if p.options.Acknowledge {
+			if !p.deliverAcknowledged(ctx, updates) {
+				return
+			}
+			continue
+		}
+
+		delivered := p.deliver(ctx, updates)
+		p.saveOffset(context.WithoutCancel(ctx))
+		if !delivered {
+			return
+		}
+	}
+}
+
+func (p *Poller) loadOffset(ctx context.Context, retry func(error) bool) bool {
+	if p.options.OffsetStore == nil {
+		return true
+	}
+
+	for {
+		offset, err := p.options.OffsetStore.LoadOffset(ctx)
+		if err == nil {
+			if offset > 0 {
+				p.config.Offset = offset
+			}
+			p.savedOffset = p.config.Offset
+			return true
+		}
+		if !retry(fmt.Errorf("load update offset: %w", err)) {
+			return false
+		}
+	}
+}
+
+func (p *Poller) saveOffset(ctx context.Context) {
+	if p.options.OffsetStore == nil || p.config.Offset == p.savedOffset {
+		return
+	}
+
+	if err := p.options.OffsetStore.SaveOffset(ctx, p.config.Offset); err != nil {
+		p.reportError(ctx, fmt.Errorf("save update offset: %w", err), 0)
+		return
+	}
+	p.savedOffset = p.config.Offset
+}
+
+// deliver sends updates to the channel, advancing the offset past every sent
+// update. It returns false if ctx is done.
+func (p *Poller) deliver(ctx context.Context, updates []Update) bool {
+	for _, update := range updates {
+		if update.UpdateID < p.config.Offset {
+			continue
+		}
+
+		select {
+		case p.updates <- update:
+			p.config.Offset = update.UpdateID + 1
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// deliverAcknowledged sends updates to the channel and waits until all of them
+// are acknowledged before advancing the offset. It returns false if ctx is
+// done.
+func (p *Poller) deliverAcknowledged(ctx context.Context, updates []Update) bool {
+	batch := make([]Update, 0, len(updates))
+	ids := make([]int, 0, len(updates))
+	for _, update := range updates {
+		if update.UpdateID >= p.config.Offset {
+			batch = append(batch, update)
+			ids = append(ids, update.UpdateID)
+		}
+	}
+	if len(batch) == 0 {
+		return true
+	}
+
+	p.ackMu.Lock()
+	p.pending = ids
+	p.ackMu.Unlock()
+
+	for _, update := range batch {
+		select {
+		case p.updates <- update:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		p.ackMu.Lock()
+		remaining := len(p.pending)
+		p.ackMu.Unlock()
+
+		if remaining == 0 {
+			break
+		}
+
+		select {
+		case <-p.acks:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	p.config.Offset = ids[len(ids)-1] + 1
+	p.savedOffset = p.config.Offset
+
+	return true
+}
+
+func (p *Poller) reportError(ctx context.Context, err error, delay time.Duration) {
+	if p.options.OnError != nil {
+		p.options.OnError(ctx, err)
+		return
+	}
+	p.bot.logUpdateError(ctx, err, delay)
+}
+
+// backoff returns a random delay between half and all of the exponential
+// backoff for the given number of consecutive failures.
+func (p *Poller) backoff(failures int) time.Duration {
+	delay := p.options.MinBackoff
+	for i := 1; i < failures && delay < p.options.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.options.MaxBackoff)
+	if p.options.MinBackoff == p.options.MaxBackoff {
+		return delay
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,290 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func updatesResponse(ids ...int) *http.Response {
+	updates := make([]string, 0, len(ids))
+	for _, id := range ids {
+		updates = append(updates, fmt.Sprintf(`{"update_id":%d,"message":{"message_id":%d,"date":1,"chat":{"id":1,"type":"private"}}}`, id, id))
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true,"result":[` + strings.Join(updates, ",") + `]}`)),
+	}
+}
+
+func requestOffset(t *testing.T, req *http.Request) string {
+	t.Helper()
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("read request body: %v", err)
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatalf("parse request body: %v", err)
+	}
+	return values.Get("offset")
+}
+
+func TestPollerDeliversUpdatesAndStopsWithContext(t *testing.T) {
+	var mu sync.Mutex
+	var offsets []string
+	polling := make(chan struct{})
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			offsets = append(offsets, requestOffset(t, req))
+			call := len(offsets)
+			mu.Unlock()
+
+			if call == 1 {
+				return updatesResponse(1, 2), nil
+			}
+			if call == 2 {
+				close(polling)
+			}
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := bot.StartPolling(ctx, NewUpdate(0), PollerConfig{})
+
+	for _, want := range []int{1, 2} {
+		update := <-poller.Updates()
+		if update.UpdateID != want {
+			t.Fatalf("expected update %d, got %d", want, update.UpdateID)
+		}
+	}
+
+	<-polling
+	cancel()
+	select {
+	case <-poller.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("poller did not stop after context cancellation")
+	}
+	if _, ok := <-poller.Updates(); ok {
+		t.Fatalf("updates channel was not closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(offsets) < 2 || offsets[0] != "" || offsets[1] != "3" {
+		t.Fatalf("unexpected offsets: %v", offsets)
+	}
+}
+
+func TestPollerStopDoesNotBlockOnFullChannel(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			return updatesResponse(1, 2, 3), nil
+		},
+	})
+
+	poller := bot.StartPolling(context.Background(), NewUpdate(0), PollerConfig{Buffer: 1})
+	<-poller.Updates()
+	poller.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		poller.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("poller blocked on a full updates channel")
+	}
+}
+
+func TestPollerReportsErrorsAndBacksOff(t *testing.T) {
+	requestErr := errors.New("network down")
+	calls := 0
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls <= 2 {
+				return nil, requestErr
+			}
+			return updatesResponse(5), nil
+		},
+	})
+
+	var reported []error
+	poller := bot.StartPolling(context.Background(), NewUpdate(0), PollerConfig{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 2 * time.Millisecond,
+		OnError: func(ctx context.Context, err error) {
+			reported = append(reported, err)
+		},
+	})
+	defer poller.Stop()
+
+	update := <-poller.Updates()
+	if update.UpdateID != 5 {
+		t.Fatalf("unexpected update: %d", update.UpdateID)
+	}
+	if len(reported) != 2 || !errors.Is(reported[0], requestErr) {
+		t.Fatalf("unexpected reported errors: %v", reported)
+	}
+}
+
+func TestPollerBackoffGrowsWithJitter(t *testing.T) {
+	poller := &Poller{options: PollerConfig{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	tests := []struct {
+		failures int
+		max      time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			delay := poller.backoff(test.failures)
+			if delay < test.max/2 || delay > test.max {
+				t.Fatalf("failures %d: delay %s outside [%s, %s]", test.failures, delay, test.max/2, test.max)
+			}
+		}
+	}
+}
+
+func TestPollerFixedBackoff(t *testing.T) {
+	poller := &Poller{options: PollerConfig{MinBackoff: 3 * time.Second, MaxBackoff: 3 * time.Second}}
+
+	for failures := 1; failures < 5; failures++ {
+		if delay := poller.backoff(failures); delay != 3*time.Second {
+			t.Fatalf("failures %d: expected a fixed 3s delay, got %s", failures, delay)
+		}
+	}
+}
+
+func TestPollerLoadsAndSavesOffset(t *testing.T) {
+	var mu sync.Mutex
+	var offsets []string
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			offsets = append(offsets, requestOffset(t, req))
+			call := len(offsets)
+			mu.Unlock()
+
+			if call == 1 {
+				return updatesResponse(10, 11), nil
+			}
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		},
+	})
+
+	store := NewMemoryOffsetStore(10)
+	poller := bot.StartPolling(context.Background(), NewUpdate(0), PollerConfig{OffsetStore: store})
+	<-poller.Updates()
+	<-poller.Updates()
+	poller.Stop()
+	poller.Wait()
+
+	if offset, _ := store.LoadOffset(context.Background()); offset != 12 {
+		t.Fatalf("expected stored offset 12, got %d", offset)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if offsets[0] != "10" {
+		t.Fatalf("stored offset was not loaded: %v", offsets)
+	}
+}
+
+func TestPollerAcknowledgmentMode(t *testing.T) {
+	var mu sync.Mutex
+	var offsets []string
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			offsets = append(offsets, requestOffset(t, req))
+			call := len(offsets)
+			mu.Unlock()
+
+			switch call {
+			case 1:
+				return updatesResponse(1, 2, 3), nil
+			case 2:
+				return updatesResponse(4), nil
+			default:
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+		},
+	})
+
+	store := NewMemoryOffsetStore(0)
+	poller := bot.StartPolling(context.Background(), NewUpdate(0), PollerConfig{
+		OffsetStore: store,
+		Acknowledge: true,
+	})
+	defer poller.Stop()
+
+	ctx := context.Background()
+	batch := []Update{<-poller.Updates(), <-poller.Updates(), <-poller.Updates()}
+
+	if err := poller.Ack(ctx, 2); err != nil {
+		t.Fatalf("ack 2: %v", err)
+	}
+	if offset, _ := store.LoadOffset(ctx); offset != 0 {
+		t.Fatalf("offset committed past unacknowledged update: %d", offset)
+	}
+	if err := poller.Ack(ctx, 1); err != nil {
+		t.Fatalf("ack 1: %v", err)
+	}
+	if offset, _ := store.LoadOffset(ctx); offset != 3 {
+		t.Fatalf("expected offset 3 after contiguous acks, got %d", offset)
+	}
+
+	select {
+	case update := <-poller.Updates():
+		t.Fatalf("next batch delivered before acknowledgment: %d", update.UpdateID)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	handler := ChainUpdateMiddleware(func(ctx context.Context, update Update) error {
+		return nil
+	}, poller.AckUpdates())
+	if err := handler(ctx, batch[2]); err != nil {
+		t.Fatalf("ack middleware: %v", err)
+	}
+
+	if update := <-poller.Updates(); update.UpdateID != 4 {
+		t.Fatalf("unexpected update after acknowledgment: %d", update.UpdateID)
+	}
+	if offset, _ := store.LoadOffset(ctx); offset != 4 {
+		t.Fatalf("expected offset 4, got %d", offset)
+	}
+	if err := poller.Ack(ctx, 99); err == nil {
+		t.Fatalf("expected error acknowledging unknown update")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(offsets) < 2 || offsets[1] != "4" {
+		t.Fatalf("unexpected request offsets: %v", offsets)
+	}
+}
@@ -0,0 +1,186 @@
+package tgbotapi
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// UploadProgress describes the progress of a file upload.
+type UploadProgress struct {
+	// Method is the Bot API method uploading the file, such as "sendVideo".
+	Method string
+	// Field is the request field of the file, such as "video".
+	Field string
+	// FileName is the name the file is uploaded with.
+	FileName string
+	// Written is the number of bytes of the file written to the request.
+	Written int64
+	// Total is the size of the file in bytes, or -1 if it isn't known.
+	Total int64
+	// Elapsed is the time since the upload of the file started.
+	Elapsed time.Duration
+	// Done reports whether the whole file was written.
+	Done bool
+}
+
+// BytesPerSecond returns the average upload throughput so far.
+func (p UploadProgress) BytesPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Written) / p.Elapsed.Seconds()
+}
+
+// UploadProgressFunc is called as files are uploaded. It is called from the
+// goroutine writing the request body and should return quickly.
+type UploadProgressFunc func(UploadProgress)
+
+// WithUploadProgress configures a function reporting the progress of every
+// file uploaded by the bot.
+//
+// Written only grows as the HTTP client reads the request body, so a stalled
+// connection stops the reports. Retried requests report the upload again
+// from the start.
+func WithUploadProgress(progress UploadProgressFunc) BotAPIOption {
+	return func(config *botAPIConfig) error {
+		config.uploadProgress = progress
+		return nil
+	}
+}
+
+type uploadProgressKey struct{}
+
+// ContextWithUploadProgress returns a context reporting the progress of the
+// files uploaded by requests made with it. It is called in addition to the
+// function configured with WithUploadProgress.
+func ContextWithUploadProgress(ctx context.Context, progress UploadProgressFunc) context.Context {
+	return context.WithValue(ctx, uploadProgressKey{}, progress)
+}
+
+// uploadProgressFunc returns the function reporting the progress of the
+// uploads of a request to method, or nil if nothing reports it.
+func (bot *BotAPI) uploadProgressFunc(ctx context.Context, method string) UploadProgressFunc {
+	botProgress := bot.uploadProgress
+	requestProgress, _ := ctx.Value(uploadProgressKey{}).(UploadProgressFunc)
+	if botProgress == nil && requestProgress == nil {
+		return nil
+	}
+
+	return func(progress UploadProgress) {
+		progress.Method = method
+		if botProgress != nil {
+			botProgress(progress)
+		}
+		if requestProgress != nil {
+			requestProgress(progress)
+		}
+	}
+}
+
+// progressWriter reports the bytes written through it.
+type progressWriter struct {
+	writer   io.Writer
+	start    time.Time
+	progress UploadProgress
+	report   UploadProgressFunc
+}
+
+func newProgressWriter(writer io.Writer, field, fileName string, data RequestFileData, report UploadProgressFunc) *progressWriter {
+	return &progressWriter{
+		writer: writer,
+		start:  time.Now(),
+		progress: UploadProgress{
+			Field:    field,
+			FileName: fileName,
+			Total:    uploadSize(data),
+		},
+		report: report,
+	}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if n > 0 {
+		w.progress.Written += int64(n)
+		w.progress.Elapsed = time.Since(w.start)
+		w.report(w.progress)
+	}
+	return n, err
+}
+
+// done reports that the whole file was written.
+func (w *progressWriter) done() {
+	w.progress.Elapsed = time.Since(w.start)
+	w.progress.Done = true
+	w.report(w.progress)
+}
+
+// uploadSize returns the size of the file to upload, or -1 if it isn't known.
+func uploadSize(data RequestFileData) int64 {
+	switch file := data.(type) {
+	case FileBytes:
+		return int64(len(file.Bytes))
+	case FilePath:
+		info, err := os.Stat(string(file))
+		if err != nil {
+			return -1
+		}
+		return info.Size()
+	case CachedFile:
+		return uploadSize(file.File)
+	}
+	return -1
+}
+
+// uploadChatActions are the chat actions shown while uploading the files of
+// a method.
+var uploadChatActions = map[string]string{
+	"sendPhoto":      ChatUploadPhoto,
+	"sendLivePhoto":  ChatUploadPhoto,
+	"sendVideo":      ChatUploadVideo,
+	"sendAnimation":  ChatUploadVideo,
+	"sendAudio":      ChatUploadVoice,
+	"sendVoice":      ChatUploadVoice,
+	"sendVideoNote":  ChatUploadVideoNote,
+	"sendDocument":   ChatUploadDocument,
+	"sendMediaGroup": ChatUploadDocument,
+}
+
+// UploadChatAction returns request middleware showing a chat action, such as
+// ChatUploadVideo for sendVideo or ChatUploadDocument for sendDocument, in
+// the chat while the files of a request are uploaded. The chat action is
+// sent again until the request completes, and errors sending it are ignored.
+//
+// Requests without uploaded files are passed on unchanged. Add the
+// middleware after CacheFileIDs so that files sent by a cached ID don't
+// show a chat action.
+func UploadChatAction() RequestMiddleware {
+	return uploadChatAction(chatActionInterval)
+}
+
+func uploadChatAction(interval time.Duration) RequestMiddleware {
+	return func(next RequestHandlerFunc) RequestHandlerFunc {
+		return func(ctx context.Context, request APIRequest) (*APIResponse, error) {
+			action, ok := uploadChatActions[request.Method]
+			if !ok || len(request.Files) == 0 || request.Params["chat_id"] == "" {
+				return next(ctx, request)
+			}
+
+			params := Params{
+				"chat_id": request.Params["chat_id"],
+				"action":  action,
+			}
+			params.AddNonEmpty("message_thread_id", request.Params["message_thread_id"])
+			params.AddNonEmpty("business_connection_id", request.Params["business_connection_id"])
+
+			stop := keepChatAction(ctx, interval, func(ctx context.Context) {
+				_, _ = next(ctx, APIRequest{Method: "sendChatAction", Params: params})
+			})
+			defer stop()
+
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUploadProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100_000)
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseMultipartForm(1 << 20); err != nil {
+				return nil, err
+			}
+			return resultResponse(`{"message_id":1}`), nil
+		},
+	})
+
+	var botReports, requestReports []UploadProgress
+	bot.uploadProgress = func(progress UploadProgress) {
+		botReports = append(botReports, progress)
+	}
+	ctx := ContextWithUploadProgress(context.Background(), func(progress UploadProgress) {
+		requestReports = append(requestReports, progress)
+	})
+
+	document := NewDocument(1, FileBytes{Name: "file.bin", Bytes: content})
+	if _, err := bot.RequestWithContext(ctx, document); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(botReports) < 2 || len(requestReports) != len(botReports) {
+		t.Fatalf("expected several reports to both functions, got %d and %d", len(botReports), len(requestReports))
+	}
+	for i := 1; i < len(botReports); i++ {
+		if botReports[i].Written < botReports[i-1].Written {
+			t.Fatalf("progress went backwards: %+v", botReports)
+		}
+	}
+
+	last := botReports[len(botReports)-1]
+	want := UploadProgress{
+		Method:   "sendDocument",
+		Field:    "document",
+		FileName: "file.bin",
+		Written:  int64(len(content)),
+		Total:    int64(len(content)),
+		Elapsed:  last.Elapsed,
+		Done:     true,
+	}
+	if last != want {
+		t.Fatalf("unexpected last report %+v, want %+v", last, want)
+	}
+}
+
+func TestUploadSize(t *testing.T) {
+	if size := uploadSize(CachedFile{File: FileBytes{Bytes: []byte("abc")}}); size != 3 {
+		t.Fatalf("expected the size of the bytes, got %d", size)
+	}
+	if size := uploadSize(FileReader{Reader: bytes.NewReader(nil)}); size != -1 {
+		t.Fatalf("expected an unknown size for readers, got %d", size)
+	}
+	if size := uploadSize(FilePath("does-not-exist")); size != -1 {
+		t.Fatalf("expected an unknown size for missing files, got %d", size)
+	}
+}
+
+func TestUploadChatAction(t *testing.T) {
+	var mu sync.Mutex
+	var actions []string
+	sent := false
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/bottoken/sendChatAction" {
+				if err := req.ParseForm(); err != nil {
+					return nil, err
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if sent {
+					t.Error("chat action sent after the upload")
+				}
+				actions = append(actions, req.PostForm.Get("chat_id")+":"+req.PostForm.Get("message_thread_id")+":"+req.PostForm.Get("action"))
+				return resultResponse("true"), nil
+			}
+
+			if req.URL.Path == "/bottoken/sendVideo" && req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return resultResponse(`{"message_id":1}`), nil
+		},
+	})
+	bot.requestMiddleware = []RequestMiddleware{uploadChatAction(10 * time.Millisecond)}
+
+	video := NewVideo(1, FileBytes{Name: "video.mp4", Bytes: []byte("video")})
+	video.MessageThreadID = 2
+	if _, err := bot.Send(video); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	sent = true
+	mu.Unlock()
+	if _, err := bot.Send(NewVideo(1, FileID("video"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Send(NewMessage(1, "text")); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(actions) < 2 {
+		t.Fatalf("expected the chat action to be refreshed, got %q", actions)
+	}
+	for _, action := range actions {
+		if action != "1:2:upload_video" {
+			t.Fatalf("unexpected chat action %q", action)
+		}
+	}
+}
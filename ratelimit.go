@@ -0,0 +1,220 @@
+package tgbotapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides when an outgoing request may be sent.
+//
+// Wait is called before every request, including retries. chatID is the
+// raw chat_id parameter of the request and is empty if the request has none.
+// Wait must block until the request may be sent or ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context, method, chatID string) error
+}
+
+// Rate is a number of requests allowed per interval.
+type Rate struct {
+	Limit    int
+	Interval time.Duration
+}
+
+// RateLimits configures the limits of a ChatRateLimiter.
+// A zero Rate disables the corresponding limit.
+type RateLimits struct {
+	// Global limits the requests sending messages to any chat.
+	Global Rate
+	// PrivateChat limits the requests sending messages to a single private
+	// chat.
+	PrivateChat Rate
+	// GroupChat limits the requests sending messages to a single group,
+	// supergroup or channel.
+	GroupChat Rate
+}
+
+// DefaultRateLimits returns the limits documented by Telegram: 30 messages
+// per second overall, one message per second to a private chat and
+// 20 messages per minute to a group.
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Global:      Rate{Limit: 30, Interval: time.Second},
+		PrivateChat: Rate{Limit: 1, Interval: time.Second},
+		GroupChat:   Rate{Limit: 20, Interval: time.Minute},
+	}
+}
+
+// maxIdleChatBuckets is the number of per-chat buckets kept before idle
+// buckets are dropped.
+const maxIdleChatBuckets = 1024
+
+// ChatRateLimiter is a token bucket RateLimiter with a global bucket and a
+// bucket for every chat. Only requests sending messages to a chat, such as
+// sendMessage, copyMessage or forwardMessage, are limited. Other requests,
+// including sendChatAction, are sent right away.
+//
+// Every request counts as one message, even a request sending several, such
+// as sendMediaGroup, copyMessages or forwardMessages. Telegram counts each
+// message of an album, so bots sending albums should configure lower limits
+// to avoid "Too Many Requests" errors.
+//
+// Positive chat IDs are treated as private chats; negative IDs and channel
+// usernames are treated as groups.
+type ChatRateLimiter struct {
+	limits RateLimits
+
+	mu     sync.Mutex
+	global *tokenBucket
+	chats  map[string]*tokenBucket
+	now    func() time.Time
+}
+
+// NewRateLimiter creates a ChatRateLimiter with the given limits.
+func NewRateLimiter(limits RateLimits) *ChatRateLimiter {
+	return &ChatRateLimiter{
+		limits: limits,
+		global: newTokenBucket(limits.Global),
+		chats:  make(map[string]*tokenBucket),
+		now:    time.Now,
+	}
+}
+
+// WithRateLimiter configures a rate limiter for outgoing requests.
+//
+// Use [NewRateLimiter] with [DefaultRateLimits] to follow Telegram's
+// documented limits, or pass nil to disable rate limiting.
+func WithRateLimiter(limiter RateLimiter) BotAPIOption {
+	return func(config *botAPIConfig) error {
+		config.rateLimiter = limiter
+		return nil
+	}
+}
+
+// Wait blocks until a request to chatID may be sent or ctx is done.
+func (l *ChatRateLimiter) Wait(ctx context.Context, method, chatID string) error {
+	if chatID == "" || !sendsMessages(method) {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	chat := l.chatBucket(chatID, now)
+
+	delay := max(l.global.reserve(now), chat.reserve(now))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	if err := sleepContext(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.global.release()
+		chat.release()
+		l.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (l *ChatRateLimiter) chatBucket(chatID string, now time.Time) *tokenBucket {
+	if bucket, ok := l.chats[chatID]; ok {
+		return bucket
+	}
+
+	if len(l.chats) >= maxIdleChatBuckets {
+		for id, bucket := range l.chats {
+			if bucket.idle(now) {
+				delete(l.chats, id)
+			}
+		}
+	}
+
+	rate := l.limits.GroupChat
+	if isPrivateChatID(chatID) {
+		rate = l.limits.PrivateChat
+	}
+
+	bucket := newTokenBucket(rate)
+	l.chats[chatID] = bucket
+	return bucket
+}
+
+// sendsMessages reports whether method sends messages to a chat.
+func sendsMessages(method string) bool {
+	if method == "sendChatAction" {
+		return false
+	}
+	return strings.HasPrefix(method, "send") ||
+		strings.HasPrefix(method, "copy") ||
+		strings.HasPrefix(method, "forward")
+}
+
+func isPrivateChatID(chatID string) bool {
+	if strings.HasPrefix(chatID, "@") {
+		return false
+	}
+	id, err := strconv.ParseInt(chatID, 10, 64)
+	return err == nil && id > 0
+}
+
+// tokenBucket is a token bucket that allows reserving tokens ahead of time.
+// The token count goes negative while reservations are waiting.
+type tokenBucket struct {
+	burst    float64
+	perToken time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate Rate) *tokenBucket {
+	if rate.Limit <= 0 || rate.Interval <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		burst:    float64(rate.Limit),
+		perToken: rate.Interval / time.Duration(rate.Limit),
+		tokens:   float64(rate.Limit),
+	}
+}
+
+func (b *tokenBucket) advance(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+float64(now.Sub(b.last))/float64(b.perToken))
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.advance(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.perToken))
+}
+
+// release returns a reserved token that was not used.
+func (b *tokenBucket) release() {
+	if b == nil {
+		return
+	}
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+func (b *tokenBucket) idle(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.advance(now)
+	return b.tokens >= b.burst
+}
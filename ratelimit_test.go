@@ -0,0 +1,145 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type recordingRateLimiter struct {
+	calls []string
+	err   error
+}
+
+func (l *recordingRateLimiter) Wait(ctx context.Context, method, chatID string) error {
+	l.calls = append(l.calls, method+":"+chatID)
+	return l.err
+}
+
+func TestChatRateLimiterDistinguishesChatKinds(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := NewRateLimiter(RateLimits{
+		Global:      Rate{Limit: 100, Interval: time.Second},
+		PrivateChat: Rate{Limit: 1, Interval: time.Second},
+		GroupChat:   Rate{Limit: 20, Interval: time.Minute},
+	})
+	limiter.now = func() time.Time { return now }
+
+	reserve := func(chatID string) time.Duration {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+		return max(limiter.global.reserve(now), limiter.chatBucket(chatID, now).reserve(now))
+	}
+
+	if delay := reserve("123"); delay != 0 {
+		t.Fatalf("first private request delayed by %s", delay)
+	}
+	if delay := reserve("123"); delay != time.Second {
+		t.Fatalf("expected second private request to wait 1s, got %s", delay)
+	}
+	if delay := reserve("456"); delay != 0 {
+		t.Fatalf("other private chat delayed by %s", delay)
+	}
+
+	for i := 0; i < 20; i++ {
+		if delay := reserve("-100123"); delay != 0 {
+			t.Fatalf("group request %d delayed by %s", i, delay)
+		}
+	}
+	if delay := reserve("-100123"); delay != 3*time.Second {
+		t.Fatalf("expected group request to wait 3s, got %s", delay)
+	}
+	if delay := reserve("@channel"); delay != 0 {
+		t.Fatalf("channel request delayed by %s", delay)
+	}
+}
+
+func TestChatRateLimiterWaitHonoursContext(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{
+		PrivateChat: Rate{Limit: 1, Interval: time.Hour},
+	})
+
+	if err := limiter.Wait(context.Background(), "sendMessage", "123"); err != nil {
+		t.Fatalf("first wait failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, "sendMessage", "123"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if err := limiter.Wait(context.Background(), "getMe", ""); err != nil {
+		t.Fatalf("request without chat was limited: %v", err)
+	}
+}
+
+func TestChatRateLimiterOnlyLimitsMessages(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{
+		Global:      Rate{Limit: 1, Interval: time.Hour},
+		PrivateChat: Rate{Limit: 1, Interval: time.Hour},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	for _, method := range []string{"getChat", "sendChatAction", "deleteMessage", "editMessageText", "sendMessage"} {
+		if err := limiter.Wait(ctx, method, "123"); err != nil {
+			t.Fatalf("%s was limited: %v", method, err)
+		}
+	}
+	if err := limiter.Wait(ctx, "copyMessage", "123"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected copyMessage to be limited, got %v", err)
+	}
+}
+
+func TestRequestUsesRateLimiter(t *testing.T) {
+	sent := 0
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			sent++
+			return okAPIResponse(), nil
+		},
+	})
+	limiter := &recordingRateLimiter{}
+	bot.rateLimiter = limiter
+
+	if _, err := bot.Request(NewMessage(123, "hello")); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if len(limiter.calls) != 1 || limiter.calls[0] != "sendMessage:123" {
+		t.Fatalf("unexpected limiter calls: %#v", limiter.calls)
+	}
+
+	limiter.err = context.Canceled
+	if _, err := bot.Request(NewMessage(123, "hello")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected limiter error, got %v", err)
+	}
+	if sent != 1 {
+		t.Fatalf("request was sent despite limiter error")
+	}
+}
+
+func TestWithRateLimiterConfiguresBot(t *testing.T) {
+	limiter := &recordingRateLimiter{}
+	bot, err := NewBotAPIWithOptions(
+		"token",
+		WithHTTPClient(fakeHTTPClient{
+			do: func(req *http.Request) (*http.Response, error) {
+				return okGetMeResponse(), nil
+			},
+		}),
+		WithRateLimiter(limiter),
+	)
+	if err != nil {
+		t.Fatalf("create bot: %v", err)
+	}
+	if bot.rateLimiter != limiter {
+		t.Fatalf("rate limiter not configured")
+	}
+	if len(limiter.calls) != 1 || limiter.calls[0] != "getMe:" {
+		t.Fatalf("unexpected limiter calls: %#v", limiter.calls)
+	}
+}
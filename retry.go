@@ -0,0 +1,105 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of requests rejected by
+// Telegram flood control (HTTP 429).
+//
+// The bot waits for the number of seconds given in
+// ResponseParameters.RetryAfter before sending the request again.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a single request,
+	// including the first one.
+	MaxAttempts int
+	// MaxDelay limits how long the bot waits before a retry. Requests asking
+	// for a longer wait fail immediately. Zero means no limit.
+	MaxDelay time.Duration
+	// Backoff returns the delay before the given retry attempt when Telegram
+	// does not provide RetryAfter. Defaults to exponential backoff starting
+	// at one second.
+	Backoff func(attempt int) time.Duration
+}
+
+// WithRetryPolicy enables automatic retries of requests rejected by
+// flood control.
+//
+// Requests uploading a FileReader are never retried because the reader
+// cannot be consumed twice.
+func WithRetryPolicy(policy RetryPolicy) BotAPIOption {
+	return func(config *botAPIConfig) error {
+		if policy.MaxAttempts < 1 {
+			return errors.New("retry policy requires at least one attempt")
+		}
+		config.retryPolicy = &policy
+		return nil
+	}
+}
+
+// retryDelay reports whether a request that failed with err should be
+// attempted again and how long to wait before doing so.
+func (p *RetryPolicy) retryDelay(ctx context.Context, err error, attempt int, files []RequestFile) (time.Duration, bool) {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if !filesReplayable(files) {
+		return 0, false
+	}
+
+	delay := time.Duration(apiErr.RetryAfter) * time.Second
+	if delay <= 0 {
+		delay = p.backoff(attempt)
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return 0, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff != nil {
+		return p.Backoff(attempt)
+	}
+	return time.Second << min(attempt-1, 6)
+}
+
+// filesReplayable reports whether the upload payload can be rebuilt for
+// another attempt.
+func filesReplayable(files []RequestFile) bool {
+	for _, file := range files {
+		data := file.Data
+		if cached, ok := data.(CachedFile); ok {
+			data = cached.File
+		}
+		switch data.(type) {
+		case FileReader, *FileReader:
+			return false
+		}
+	}
+	return true
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
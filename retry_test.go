@@ -0,0 +1,179 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func floodControlResponse(retryAfter int) *http.Response {
+	body := fmt.Sprintf(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after %d","parameters":{"retry_after":%d}}`, retryAfter, retryAfter)
+	return &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func newRetryBot(client HTTPClient, policy RetryPolicy) *BotAPI {
+	bot := newFakeBot(client)
+	bot.retryPolicy = &policy
+	bot.loggingDisabled = true
+	return bot
+}
+
+func TestRetryPolicyRetriesFloodControl(t *testing.T) {
+	var bodies []string
+	bot := newRetryBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				t.Fatalf("read request body: %v", err)
+			}
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				return floodControlResponse(0), nil
+			}
+			return okAPIResponse(), nil
+		},
+	}, RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+	})
+
+	config := NewPhoto(123, FileBytes{Name: "photo.jpg", Bytes: []byte("image-bytes")})
+	if _, err := bot.Request(config); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if !strings.Contains(body, "image-bytes") {
+			t.Fatalf("attempt %d did not rebuild multipart payload: %q", i+1, body)
+		}
+	}
+}
+
+func TestRetryPolicyStopsAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	bot := newRetryBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return floodControlResponse(0), nil
+		},
+	}, RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+	})
+
+	_, err := bot.Request(NewMessage(123, "hello"))
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected flood control error, got %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicySkipsUnreplayableAndSlowRetries(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    func() (context.Context, context.CancelFunc)
+		config Chattable
+		after  int
+		policy RetryPolicy
+	}{
+		{
+			name:   "file reader",
+			ctx:    func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			config: NewPhoto(123, FileReader{Name: "photo.jpg", Reader: strings.NewReader("image")}),
+			policy: RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration { return time.Millisecond }},
+		},
+		{
+			name: "context deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Second)
+			},
+			config: NewMessage(123, "hello"),
+			after:  5,
+			policy: RetryPolicy{MaxAttempts: 3},
+		},
+		{
+			name:   "max delay",
+			ctx:    func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			config: NewMessage(123, "hello"),
+			after:  5,
+			policy: RetryPolicy{MaxAttempts: 3, MaxDelay: time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			bot := newRetryBot(fakeHTTPClient{
+				do: func(req *http.Request) (*http.Response, error) {
+					attempts++
+					_, _ = io.Copy(io.Discard, req.Body)
+					return floodControlResponse(test.after), nil
+				},
+			}, test.policy)
+
+			ctx, cancel := test.ctx()
+			defer cancel()
+
+			if _, err := bot.RequestWithContext(ctx, test.config); err == nil {
+				t.Fatalf("expected flood control error")
+			}
+			if attempts != 1 {
+				t.Fatalf("expected a single attempt, got %d", attempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyLogsAttempts(t *testing.T) {
+	var records []slog.Record
+	attempts := 0
+	bot := newRetryBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts == 1 {
+				return floodControlResponse(0), nil
+			}
+			return okAPIResponse(), nil
+		},
+	}, RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+	})
+	bot.loggingDisabled = false
+	bot.logger = slog.New(recordingSlogHandler{records: &records})
+
+	if _, err := bot.Request(NewMessage(123, "hello")); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if len(records) != 1 || records[0].Message != "telegram request retry scheduled" || records[0].Level != slog.LevelWarn {
+		t.Fatalf("unexpected log records: %+v", records)
+	}
+}
+
+func TestWithRetryPolicyRequiresAttempts(t *testing.T) {
+	config := defaultBotAPIConfig()
+	if err := WithRetryPolicy(RetryPolicy{})(&config); err == nil {
+		t.Fatalf("expected error for empty retry policy")
+	}
+	if err := WithRetryPolicy(RetryPolicy{MaxAttempts: 2})(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.retryPolicy == nil || config.retryPolicy.MaxAttempts != 2 {
+		t.Fatalf("retry policy not applied: %+v", config.retryPolicy)
+	}
+}
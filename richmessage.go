@@ -0,0 +1,434 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Rich text types, used as the Type of RichText values.
+const (
+	RichTextTypeBold                   = "bold"
+	RichTextTypeItalic                 = "italic"
+	RichTextTypeUnderline              = "underline"
+	RichTextTypeStrikethrough          = "strikethrough"
+	RichTextTypeSpoiler                = "spoiler"
+	RichTextTypeDateTime               = "date_time"
+	RichTextTypeTextMention            = "text_mention"
+	RichTextTypeSubscript              = "subscript"
+	RichTextTypeSuperscript            = "superscript"
+	RichTextTypeMarked                 = "marked"
+	RichTextTypeCode                   = "code"
+	RichTextTypeCustomEmoji            = "custom_emoji"
+	RichTextTypeMathematicalExpression = "mathematical_expression"
+	RichTextTypeUrl                    = "url"
+	RichTextTypeEmailAddress           = "email_address"
+	RichTextTypePhoneNumber            = "phone_number"
+	RichTextTypeBankCardNumber         = "bank_card_number"
+	RichTextTypeMention                = "mention"
+	RichTextTypeHashtag                = "hashtag"
+	RichTextTypeCashtag                = "cashtag"
+	RichTextTypeBotCommand             = "bot_command"
+	RichTextTypeAnchor                 = "anchor"
+	RichTextTypeAnchorLink             = "anchor_link"
+	RichTextTypeReference              = "reference"
+	RichTextTypeReferenceLink          = "reference_link"
+)
+
+// Rich block types, used as the Type of RichBlock values.
+const (
+	RichBlockTypeParagraph              = "paragraph"
+	RichBlockTypeSectionHeading         = "heading"
+	RichBlockTypePreformatted           = "pre"
+	RichBlockTypeFooter                 = "footer"
+	RichBlockTypeDivider                = "divider"
+	RichBlockTypeMathematicalExpression = "mathematical_expression"
+	RichBlockTypeAnchor                 = "anchor"
+	RichBlockTypeList                   = "list"
+	RichBlockTypeBlockQuotation         = "blockquote"
+	RichBlockTypePullQuotation          = "pullquote"
+	RichBlockTypeCollage                = "collage"
+	RichBlockTypeSlideshow              = "slideshow"
+	RichBlockTypeTable                  = "table"
+	RichBlockTypeDetails                = "details"
+	RichBlockTypeMap                    = "map"
+	RichBlockTypeAnimation              = "animation"
+	RichBlockTypeAudio                  = "audio"
+	RichBlockTypePhoto                  = "photo"
+	RichBlockTypeVideo                  = "video"
+	RichBlockTypeVoiceNote              = "voice_note"
+	RichBlockTypeThinking               = "thinking"
+)
+
+// richTextDecoders and richBlockDecoders map the types of rich text and
+// blocks to their decoders. They are set in init because the decoders
+// decode nested text and blocks through them.
+var richTextDecoders, richBlockDecoders map[string]func([]byte) (any, error)
+
+func init() {
+	richTextDecoders = map[string]func([]byte) (any, error){
+		RichTextTypeBold:                   decodeRichNode[RichTextBold],
+		RichTextTypeItalic:                 decodeRichNode[RichTextItalic],
+		RichTextTypeUnderline:              decodeRichNode[RichTextUnderline],
+		RichTextTypeStrikethrough:          decodeRichNode[RichTextStrikethrough],
+		RichTextTypeSpoiler:                decodeRichNode[RichTextSpoiler],
+		RichTextTypeDateTime:               decodeRichNode[RichTextDateTime],
+		RichTextTypeTextMention:            decodeRichNode[RichTextTextMention],
+		RichTextTypeSubscript:              decodeRichNode[RichTextSubscript],
+		RichTextTypeSuperscript:            decodeRichNode[RichTextSuperscript],
+		RichTextTypeMarked:                 decodeRichNode[RichTextMarked],
+		RichTextTypeCode:                   decodeRichNode[RichTextCode],
+		RichTextTypeCustomEmoji:            decodeRichNode[RichTextCustomEmoji],
+		RichTextTypeMathematicalExpression: decodeRichNode[RichTextMathematicalExpression],
+		RichTextTypeUrl:                    decodeRichNode[RichTextUrl],
+		RichTextTypeEmailAddress:           decodeRichNode[RichTextEmailAddress],
+		RichTextTypePhoneNumber:            decodeRichNode[RichTextPhoneNumber],
+		RichTextTypeBankCardNumber:         decodeRichNode[RichTextBankCardNumber],
+		RichTextTypeMention:                decodeRichNode[RichTextMention],
+		RichTextTypeHashtag:                decodeRichNode[RichTextHashtag],
+		RichTextTypeCashtag:                decodeRichNode[RichTextCashtag],
+		RichTextTypeBotCommand:             decodeRichNode[RichTextBotCommand],
+		RichTextTypeAnchor:                 decodeRichNode[RichTextAnchor],
+		RichTextTypeAnchorLink:             decodeRichNode[RichTextAnchorLink],
+		RichTextTypeReference:              decodeRichNode[RichTextReference],
+		RichTextTypeReferenceLink:          decodeRichNode[RichTextReferenceLink],
+	}
+
+	richBlockDecoders = map[string]func([]byte) (any, error){
+		RichBlockTypeParagraph:              decodeRichNode[RichBlockParagraph],
+		RichBlockTypeSectionHeading:         decodeRichNode[RichBlockSectionHeading],
+		RichBlockTypePreformatted:           decodeRichNode[RichBlockPreformatted],
+		RichBlockTypeFooter:                 decodeRichNode[RichBlockFooter],
+		RichBlockTypeDivider:                decodeRichNode[RichBlockDivider],
+		RichBlockTypeMathematicalExpression: decodeRichNode[RichBlockMathematicalExpression],
+		RichBlockTypeAnchor:                 decodeRichNode[RichBlockAnchor],
+		RichBlockTypeList:                   decodeRichNode[RichBlockList],
+		RichBlockTypeBlockQuotation:         decodeRichNode[RichBlockBlockQuotation],
+		RichBlockTypePullQuotation:          decodeRichNode[RichBlockPullQuotation],
+		RichBlockTypeCollage:                decodeRichNode[RichBlockCollage],
+		RichBlockTypeSlideshow:              decodeRichNode[RichBlockSlideshow],
+		RichBlockTypeTable:                  decodeRichNode[RichBlockTable],
+		RichBlockTypeDetails:                decodeRichNode[RichBlockDetails],
+		RichBlockTypeMap:                    decodeRichNode[RichBlockMap],
+		RichBlockTypeAnimation:              decodeRichNode[RichBlockAnimation],
+		RichBlockTypeAudio:                  decodeRichNode[RichBlockAudio],
+		RichBlockTypePhoto:                  decodeRichNode[RichBlockPhoto],
+		RichBlockTypeVideo:                  decodeRichNode[RichBlockVideo],
+		RichBlockTypeVoiceNote:              decodeRichNode[RichBlockVoiceNote],
+		RichBlockTypeThinking:               decodeRichNode[RichBlockThinking],
+	}
+}
+
+// DecodeRichText decodes a rich text value.
+//
+// Plain text is decoded as a string and a concatenation of texts as
+// []RichText. Formatted text is decoded as the RichText struct matching its
+// type, such as RichTextBold. Text of an unknown type is kept as
+// json.RawMessage.
+func DecodeRichText(data []byte) (RichText, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	switch data[0] {
+	case '"':
+		var text string
+		err := json.Unmarshal(data, &text)
+		return text, err
+	case '[':
+		var raw []json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		texts := make([]RichText, len(raw))
+		for i, item := range raw {
+			text, err := DecodeRichText(item)
+			if err != nil {
+				return nil, err
+			}
+			texts[i] = text
+		}
+		return texts, nil
+	}
+
+	return decodeRichObject(data, richTextDecoders, "rich text")
+}
+
+// DecodeRichBlock decodes a rich block into the RichBlock struct matching its
+// type, such as RichBlockParagraph. A block of an unknown type is kept as
+// json.RawMessage.
+func DecodeRichBlock(data []byte) (RichBlock, error) {
+	return decodeRichObject(bytes.TrimSpace(data), richBlockDecoders, "rich block")
+}
+
+func decodeRichObject(data []byte, decoders map[string]func([]byte) (any, error), kind string) (any, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", kind, err)
+	}
+
+	decode, ok := decoders[head.Type]
+	if !ok {
+		return append(json.RawMessage(nil), data...), nil
+	}
+
+	node, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s %q: %w", kind, head.Type, err)
+	}
+	return node, nil
+}
+
+func decodeRichNode[T any](data []byte) (any, error) {
+	var node T
+	if err := decodeRichStruct(data, &node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+var (
+	richTextType   = reflect.TypeFor[RichText]()
+	richBlocksType = reflect.TypeFor[[]RichBlock]()
+)
+
+// decodeRichStruct decodes data into the struct v points to, decoding its
+// RichText and []RichBlock fields with DecodeRichText and DecodeRichBlock.
+func decodeRichStruct(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		fieldData, ok := raw[name]
+		if !ok {
+			continue
+		}
+
+		switch field.Type {
+		case richTextType:
+			text, err := DecodeRichText(fieldData)
+			if err != nil {
+				return err
+			}
+			value.Field(i).Set(reflect.ValueOf(&text).Elem())
+		case richBlocksType:
+			blocks, err := decodeRichBlocks(fieldData)
+			if err != nil {
+				return err
+			}
+			value.Field(i).Set(reflect.ValueOf(blocks))
+		}
+	}
+	return nil
+}
+
+func decodeRichBlocks(data json.RawMessage) ([]RichBlock, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || raw == nil {
+		return nil, err
+	}
+
+	blocks := make([]RichBlock, len(raw))
+	for i, item := range raw {
+		block, err := DecodeRichBlock(item)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = block
+	}
+	return blocks, nil
+}
+
+// UnmarshalJSON decodes RichMessage with its nested blocks converted to typed values.
+func (m *RichMessage) UnmarshalJSON(data []byte) error {
+	type alias RichMessage
+	return decodeRichStruct(data, (*alias)(m))
+}
+
+// UnmarshalJSON decodes RichBlockCaption with its rich text converted to typed values.
+func (b *RichBlockCaption) UnmarshalJSON(data []byte) error {
+	type alias RichBlockCaption
+	return decodeRichStruct(data, (*alias)(b))
+}
+
+// UnmarshalJSON decodes RichBlockTableCell with its rich text converted to typed values.
+func (b *RichBlockTableCell) UnmarshalJSON(data []byte) error {
+	type alias RichBlockTableCell
+	return decodeRichStruct(data, (*alias)(b))
+}
+
+// UnmarshalJSON decodes RichBlockListItem with its nested blocks converted to typed values.
+func (b *RichBlockListItem) UnmarshalJSON(data []byte) error {
+	type alias RichBlockListItem
+	return decodeRichStruct(data, (*alias)(b))
+}
+
+// Walk calls visit for every block and text of the message in depth-first
+// order. See WalkRich for details.
+func (m RichMessage) Walk(visit func(node any) bool) {
+	walkRichBlocks(m.Blocks, visit)
+}
+
+// WalkRich traverses a rich message tree in depth-first order. It calls visit
+// for node and then for each of its children: nested blocks and texts, list
+// items, table cells and captions. Plain text is visited as a string.
+//
+// If visit returns false, the children of the node are skipped. Slices of
+// blocks and texts are traversed without being visited themselves.
+func WalkRich(node any, visit func(node any) bool) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case RichMessage:
+		walkRichBlocks(n.Blocks, visit)
+		return
+	case *RichMessage:
+		if n != nil {
+			walkRichBlocks(n.Blocks, visit)
+		}
+		return
+	case *RichBlockCaption:
+		if n == nil {
+			return
+		}
+	case []RichBlock:
+		walkRichBlocks(n, visit)
+		return
+	case []RichText:
+		for _, text := range n {
+			WalkRich(text, visit)
+		}
+		return
+	}
+
+	if !visit(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case RichBlockCaption:
+		WalkRich(n.Text, visit)
+		WalkRich(n.Credit, visit)
+	case *RichBlockCaption:
+		WalkRich(n.Text, visit)
+		WalkRich(n.Credit, visit)
+	case RichBlockTableCell:
+		WalkRich(n.Text, visit)
+	case RichBlockListItem:
+		walkRichBlocks(n.Blocks, visit)
+
+	case RichTextBold:
+		WalkRich(n.Text, visit)
+	case RichTextItalic:
+		WalkRich(n.Text, visit)
+	case RichTextUnderline:
+		WalkRich(n.Text, visit)
+	case RichTextStrikethrough:
+		WalkRich(n.Text, visit)
+	case RichTextSpoiler:
+		WalkRich(n.Text, visit)
+	case RichTextDateTime:
+		WalkRich(n.Text, visit)
+	case RichTextTextMention:
+		WalkRich(n.Text, visit)
+	case RichTextSubscript:
+		WalkRich(n.Text, visit)
+	case RichTextSuperscript:
+		WalkRich(n.Text, visit)
+	case RichTextMarked:
+		WalkRich(n.Text, visit)
+	case RichTextCode:
+		WalkRich(n.Text, visit)
+	case RichTextUrl:
+		WalkRich(n.Text, visit)
+	case RichTextEmailAddress:
+		WalkRich(n.Text, visit)
+	case RichTextPhoneNumber:
+		WalkRich(n.Text, visit)
+	case RichTextBankCardNumber:
+		WalkRich(n.Text, visit)
+	case RichTextMention:
+		WalkRich(n.Text, visit)
+	case RichTextHashtag:
+		WalkRich(n.Text, visit)
+	case RichTextCashtag:
+		WalkRich(n.Text, visit)
+	case RichTextBotCommand:
+		WalkRich(n.Text, visit)
+	case RichTextAnchorLink:
+		WalkRich(n.Text, visit)
+	case RichTextReference:
+		WalkRich(n.Text, visit)
+	case RichTextReferenceLink:
+		WalkRich(n.Text, visit)
+
+	case RichBlockParagraph:
+		WalkRich(n.Text, visit)
+	case RichBlockSectionHeading:
+		WalkRich(n.Text, visit)
+	case RichBlockPreformatted:
+		WalkRich(n.Text, visit)
+	case RichBlockFooter:
+		WalkRich(n.Text, visit)
+	case RichBlockList:
+		for _, item := range n.Items {
+			WalkRich(item, visit)
+		}
+	case RichBlockBlockQuotation:
+		walkRichBlocks(n.Blocks, visit)
+		WalkRich(n.Credit, visit)
+	case RichBlockPullQuotation:
+		WalkRich(n.Text, visit)
+		WalkRich(n.Credit, visit)
+	case RichBlockCollage:
+		walkRichBlocks(n.Blocks, visit)
+		WalkRich(n.Caption, visit)
+	case RichBlockSlideshow:
+		walkRichBlocks(n.Blocks, visit)
+		WalkRich(n.Caption, visit)
+	case RichBlockTable:
+		for _, row := range n.Cells {
+			for _, cell := range row {
+				WalkRich(cell, visit)
+			}
+		}
+		WalkRich(n.Caption, visit)
+	case RichBlockDetails:
+		WalkRich(n.Summary, visit)
+		walkRichBlocks(n.Blocks, visit)
+	case RichBlockMap:
+		WalkRich(n.Caption, visit)
+	case RichBlockAnimation:
+		WalkRich(n.Caption, visit)
+	case RichBlockAudio:
+		WalkRich(n.Caption, visit)
+	case RichBlockPhoto:
+		WalkRich(n.Caption, visit)
+	case RichBlockVideo:
+		WalkRich(n.Caption, visit)
+	case RichBlockVoiceNote:
+		WalkRich(n.Caption, visit)
+	case RichBlockThinking:
+		WalkRich(n.Text, visit)
+	}
+}
+
+func walkRichBlocks(blocks []RichBlock, visit func(node any) bool) {
+	for _, block := range blocks {
+		WalkRich(block, visit)
+	}
+}
@@ -0,0 +1,150 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const richMessageBody = `{
+	"blocks":[
+		{"type":"heading","size":1,"text":[{"type":"bold","text":"News"}," today"]},
+		{"type":"paragraph","text":{"type":"url","url":"https://telegram.org","text":{"type":"italic","text":"link"}}},
+		{"type":"list","items":[
+			{"label":"1.","blocks":[{"type":"paragraph","text":"first"}]},
+			{"label":"2.","blocks":[{"type":"blockquote","blocks":[{"type":"paragraph","text":"quoted"}],"credit":"author"}]}
+		]},
+		{"type":"table","cells":[[{"text":{"type":"code","text":"cell"},"align":"left","valign":"top"}]],"caption":"table"},
+		{"type":"details","summary":"more","blocks":[{"type":"divider"}]},
+		{"type":"photo","photo":[{"file_id":"photo","file_unique_id":"unique","width":1,"height":1}],"caption":{"text":"photo caption"}},
+		{"type":"future_block","value":1}
+	],
+	"is_rtl":false
+}`
+
+func TestRichMessageUnmarshalTyped(t *testing.T) {
+	var message RichMessage
+	if err := json.Unmarshal([]byte(richMessageBody), &message); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(message.Blocks) != 7 {
+		t.Fatalf("expected 7 blocks, got %d", len(message.Blocks))
+	}
+
+	heading, ok := message.Blocks[0].(RichBlockSectionHeading)
+	if !ok {
+		t.Fatalf("expected heading, got %T", message.Blocks[0])
+	}
+	expectedHeading := []RichText{RichTextBold{Type: "bold", Text: "News"}, " today"}
+	if heading.Size != 1 || !reflect.DeepEqual(heading.Text, expectedHeading) {
+		t.Fatalf("unexpected heading: %#v", heading)
+	}
+
+	paragraph := message.Blocks[1].(RichBlockParagraph)
+	link, ok := paragraph.Text.(RichTextUrl)
+	if !ok || link.URL != "https://telegram.org" {
+		t.Fatalf("unexpected paragraph text: %#v", paragraph.Text)
+	}
+	if link.Text != (RichTextItalic{Type: "italic", Text: "link"}) {
+		t.Fatalf("unexpected link text: %#v", link.Text)
+	}
+
+	list := message.Blocks[2].(RichBlockList)
+	quote, ok := list.Items[1].Blocks[0].(RichBlockBlockQuotation)
+	if !ok || quote.Credit != "author" {
+		t.Fatalf("unexpected list item block: %#v", list.Items[1].Blocks[0])
+	}
+	if quote.Blocks[0] != (RichBlockParagraph{Type: "paragraph", Text: "quoted"}) {
+		t.Fatalf("unexpected quotation: %#v", quote.Blocks)
+	}
+
+	table := message.Blocks[3].(RichBlockTable)
+	if table.Cells[0][0].Text != (RichTextCode{Type: "code", Text: "cell"}) || table.Cells[0][0].Align != "left" {
+		t.Fatalf("unexpected table cell: %#v", table.Cells[0][0])
+	}
+
+	details := message.Blocks[4].(RichBlockDetails)
+	if details.Summary != "more" || details.Blocks[0] != (RichBlockDivider{Type: "divider"}) {
+		t.Fatalf("unexpected details: %#v", details)
+	}
+
+	photo := message.Blocks[5].(RichBlockPhoto)
+	if photo.Caption == nil || photo.Caption.Text != "photo caption" || photo.Photo[0].FileID != "photo" {
+		t.Fatalf("unexpected photo: %#v", photo)
+	}
+
+	unknown, ok := message.Blocks[6].(json.RawMessage)
+	if !ok || string(unknown) != `{"type":"future_block","value":1}` {
+		t.Fatalf("unexpected unknown block: %#v", message.Blocks[6])
+	}
+}
+
+func TestRichMessageRoundTrip(t *testing.T) {
+	var message RichMessage
+	if err := json.Unmarshal([]byte(richMessageBody), &message); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded RichMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(message, decoded) {
+		t.Fatalf("round trip changed message:\n%#v\n%#v", message, decoded)
+	}
+}
+
+func TestDecodeRichTextInvalid(t *testing.T) {
+	if _, err := DecodeRichText([]byte(`{"type":"bold","text":1}`)); err == nil {
+		t.Fatal("expected error for invalid text")
+	}
+	if _, err := DecodeRichBlock([]byte(`"paragraph"`)); err == nil {
+		t.Fatal("expected error for non-object block")
+	}
+}
+
+func TestWalkRich(t *testing.T) {
+	var message RichMessage
+	if err := json.Unmarshal([]byte(richMessageBody), &message); err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	message.Walk(func(node any) bool {
+		if text, ok := node.(string); ok {
+			texts = append(texts, text)
+		}
+		return true
+	})
+
+	expected := []string{"News", " today", "link", "first", "quoted", "author", "cell", "table", "more", "photo caption"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Fatalf("expected %q, got %q", expected, texts)
+	}
+}
+
+func TestWalkRichSkipChildren(t *testing.T) {
+	var message RichMessage
+	if err := json.Unmarshal([]byte(richMessageBody), &message); err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []any
+	WalkRich(message.Blocks, func(node any) bool {
+		visited = append(visited, node)
+		_, isList := node.(RichBlockList)
+		return !isList
+	})
+
+	for _, node := range visited {
+		if _, ok := node.(RichBlockListItem); ok {
+			t.Fatal("children of a skipped list were visited")
+		}
+	}
+}
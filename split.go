@@ -0,0 +1,291 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Length limits of text and captions, in UTF-16 code units.
+const (
+	MaxMessageTextLength = 4096
+	MaxCaptionLength     = 1024
+)
+
+// TextChunk is a part of a text with its entities re-based to the part.
+type TextChunk struct {
+	Text     string
+	Entities []MessageEntity
+}
+
+// textSeparators are the boundaries text is split on, in order of
+// preference. The separator itself is dropped from the split text, and the
+// empty separator splits between any two runes.
+var textSeparators = []string{"\n\n", "\n", " ", ""}
+
+// SplitText splits text into chunks of at most limit UTF-16 code units.
+//
+// Text is split on paragraphs, then lines, then words, dropping the separator
+// at each split. Splits inside an entity or a surrogate pair are avoided; an
+// entity is only split, into one entity per chunk, if it doesn't fit into a
+// chunk on its own.
+func SplitText(text string, entities []MessageEntity, limit int) []TextChunk {
+	return splitText(text, entities, limit, limit)
+}
+
+// splitText splits text like SplitText, with a separate limit for the first
+// chunk.
+func splitText(text string, entities []MessageEntity, firstLimit, limit int) []TextChunk {
+	chunk := TextChunk{Text: text, Entities: entities}
+
+	var chunks []TextChunk
+	for {
+		head, tail, ok := splitTextChunk(chunk, firstLimit)
+		chunks = append(chunks, head)
+		if !ok {
+			return chunks
+		}
+		chunk, firstLimit = tail, limit
+	}
+}
+
+// splitTextChunk splits the first chunk of at most limit UTF-16 code units
+// from chunk. It reports false if no text is left after the first chunk.
+func splitTextChunk(chunk TextChunk, limit int) (TextChunk, TextChunk, bool) {
+	text := chunk.Text
+	if UTF16Len(text) <= limit {
+		return chunk, TextChunk{}, false
+	}
+
+	// Rune boundaries within the limit, with their UTF-16 offsets.
+	var indexes, offsets []int
+	offset := 0
+	for i, r := range text {
+		if offset > limit {
+			break
+		}
+		indexes = append(indexes, i)
+		offsets = append(offsets, offset)
+		offset += utf16.RuneLen(r)
+	}
+
+	cut, cutOffset, separator := 0, 0, ""
+search:
+	for _, keepEntities := range []bool{true, false} {
+		for _, sep := range textSeparators {
+			for j := len(indexes) - 1; j > 0; j-- {
+				if !strings.HasPrefix(text[indexes[j]:], sep) {
+					continue
+				}
+				if keepEntities && splitsEntity(chunk.Entities, offsets[j], offsets[j]+len(sep)) {
+					continue
+				}
+				cut, cutOffset, separator = indexes[j], offsets[j], sep
+				break search
+			}
+		}
+	}
+	if cut == 0 {
+		// A rune longer than the limit is kept whole.
+		r, size := utf8.DecodeRuneInString(text)
+		cut, cutOffset = size, utf16.RuneLen(r)
+	}
+
+	head := TextChunk{Text: text[:cut]}
+	tail := TextChunk{Text: text[cut+len(separator):]}
+	tailOffset := cutOffset + len(separator)
+	for _, entity := range chunk.Entities {
+		start, end := entity.Offset, entity.Offset+entity.Length
+		if start < cutOffset {
+			part := entity
+			part.Length = min(end, cutOffset) - start
+			head.Entities = append(head.Entities, part)
+		}
+		if end > tailOffset {
+			part := entity
+			part.Offset = max(start, tailOffset) - tailOffset
+			part.Length = end - tailOffset - part.Offset
+			tail.Entities = append(tail.Entities, part)
+		}
+	}
+
+	return head, tail, tail.Text != ""
+}
+
+// splitsEntity reports whether an entity has text on both sides of the
+// separator between the UTF-16 offsets start and end.
+func splitsEntity(entities []MessageEntity, start, end int) bool {
+	for _, entity := range entities {
+		if entity.Offset < start && entity.Offset+entity.Length > end {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitMessage splits a message with text longer than MaxMessageTextLength
+// into messages that can be sent in order. Messages that fit are returned
+// unchanged.
+//
+// Text formatted with ParseMode is converted to entities, so only ModeHTML
+// and ModeMarkdownV2 are supported for long messages. The reply markup is
+// kept on the last message, and the reply parameters and message effect on
+// the first one.
+func SplitMessage(config MessageConfig) ([]MessageConfig, error) {
+	if UTF16Len(config.Text) <= MaxMessageTextLength {
+		return []MessageConfig{config}, nil
+	}
+
+	text, entities, err := parseSplitText(config.Text, config.ParseMode, config.Entities)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := SplitText(text, entities, MaxMessageTextLength)
+	messages := make([]MessageConfig, len(chunks))
+	for i, chunk := range chunks {
+		message := config
+		message.Text, message.ParseMode, message.Entities = chunk.Text, "", chunk.Entities
+		splitBaseChat(&message.BaseChat, i, len(chunks))
+		messages[i] = message
+	}
+
+	return messages, nil
+}
+
+// SplitCaption splits media with a caption longer than MaxCaptionLength into
+// the media with the first part of the caption, followed by messages with the
+// rest. Media with a caption that fits is returned unchanged.
+//
+// Photos, live photos, audio, documents, videos, animations, voice messages
+// and paid media are supported. ParseMode, the reply markup and the reply
+// parameters are handled as in SplitMessage.
+func SplitCaption(c Chattable) ([]Chattable, error) {
+	var base *BaseChat
+	var caption, parseMode *string
+	var entities *[]MessageEntity
+	var media func() Chattable
+
+	switch config := c.(type) {
+	case PhotoConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case SendLivePhotoConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case AudioConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case DocumentConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case VideoConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case AnimationConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case VoiceConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	case PaidMediaConfig:
+		base, caption, parseMode, entities = &config.BaseChat, &config.Caption, &config.ParseMode, &config.CaptionEntities
+		media = func() Chattable { return config }
+	default:
+		return nil, fmt.Errorf("captions of %T can't be split", c)
+	}
+
+	if UTF16Len(*caption) <= MaxCaptionLength {
+		return []Chattable{c}, nil
+	}
+
+	text, textEntities, err := parseSplitText(*caption, *parseMode, *entities)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := splitText(text, textEntities, MaxCaptionLength, MaxMessageTextLength)
+	configs := make([]Chattable, len(chunks))
+	chat := *base
+	for i, chunk := range chunks {
+		if i == 0 {
+			*caption, *parseMode, *entities = chunk.Text, "", chunk.Entities
+			splitBaseChat(base, i, len(chunks))
+			configs[i] = media()
+			continue
+		}
+
+		message := MessageConfig{BaseChat: chat, Text: chunk.Text, Entities: chunk.Entities}
+		splitBaseChat(&message.BaseChat, i, len(chunks))
+		configs[i] = message
+	}
+
+	return configs, nil
+}
+
+// parseSplitText returns the plain text and entities of text formatted with
+// parseMode.
+func parseSplitText(text, parseMode string, entities []MessageEntity) (string, []MessageEntity, error) {
+	if parseMode == "" {
+		return text, entities, nil
+	}
+	return ParseMarkup(text, parseMode)
+}
+
+// splitBaseChat adjusts the chat options of the part at index of a split
+// message with count parts.
+func splitBaseChat(chat *BaseChat, index, count int) {
+	if index > 0 {
+		chat.ReplyParameters = ReplyParameters{}
+		chat.MessageEffectID = ""
+	}
+	if index < count-1 {
+		chat.ReplyMarkup = nil
+	}
+}
+
+// SendSplit sends a message or media with a caption, split by SplitMessage
+// or SplitCaption if it's too long. See SendSplitWithContext.
+func (bot *BotAPI) SendSplit(c Chattable) ([]Message, error) {
+	return bot.SendSplitWithContext(context.Background(), c)
+}
+
+// SendSplitWithContext sends a MessageConfig split by SplitMessage, or media
+// split by SplitCaption, in order. If a part fails to send, the messages sent
+// so far are returned with the error.
+func (bot *BotAPI) SendSplitWithContext(ctx context.Context, c Chattable) ([]Message, error) {
+	var configs []Chattable
+	if config, ok := c.(MessageConfig); ok {
+		messages, err := SplitMessage(config)
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			configs = append(configs, message)
+		}
+	} else {
+		var err error
+		configs, err = SplitCaption(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	messages := make([]Message, 0, len(configs))
+	for _, config := range configs {
+		resp, err := bot.RequestWithContext(ctx, config)
+		if err != nil {
+			return messages, err
+		}
+
+		var message Message
+		if err := decodeResult(config.method(), resp.Result, &message); err != nil {
+			return messages, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
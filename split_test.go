@@ -0,0 +1,166 @@
+package tgbotapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func chunkTexts(chunks []TextChunk) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	return texts
+}
+
+func TestSplitTextBoundaries(t *testing.T) {
+	text := "aaa bbb\nccc\n\nddd eee"
+
+	tests := []struct {
+		limit    int
+		expected []string
+	}{
+		{100, []string{text}},
+		{12, []string{"aaa bbb\nccc", "ddd eee"}},
+		{8, []string{"aaa bbb", "ccc", "ddd eee"}},
+		{5, []string{"aaa", "bbb", "ccc", "ddd", "eee"}},
+		{2, []string{"aa", "a", "bb", "b", "cc", "c", "dd", "d", "ee", "e"}},
+	}
+	for _, test := range tests {
+		texts := chunkTexts(SplitText(text, nil, test.limit))
+		if !reflect.DeepEqual(texts, test.expected) {
+			t.Errorf("limit %d: unexpected chunks %q", test.limit, texts)
+		}
+	}
+}
+
+func TestSplitTextSurrogatePairs(t *testing.T) {
+	for _, limit := range []int{1, 3} {
+		texts := chunkTexts(SplitText("😀😀😀", nil, limit))
+		if !reflect.DeepEqual(texts, []string{"😀", "😀", "😀"}) {
+			t.Errorf("limit %d: unexpected chunks %q", limit, texts)
+		}
+	}
+}
+
+func TestSplitTextEntities(t *testing.T) {
+	// The space inside the bold entity is avoided.
+	chunks := SplitText("hello world foo", []MessageEntity{
+		{Type: "italic", Offset: 0, Length: 5},
+		{Type: "bold", Offset: 6, Length: 9},
+	}, 12)
+
+	expected := []TextChunk{
+		{Text: "hello", Entities: []MessageEntity{{Type: "italic", Offset: 0, Length: 5}}},
+		{Text: "world foo", Entities: []MessageEntity{{Type: "bold", Offset: 0, Length: 9}}},
+	}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Fatalf("unexpected chunks %+v", chunks)
+	}
+
+	// An entity longer than the limit is split into an entity per chunk.
+	chunks = SplitText("👋 aaaa bbbb", []MessageEntity{{Type: "bold", Offset: 3, Length: 9}}, 8)
+
+	expected = []TextChunk{
+		{Text: "👋"},
+		{Text: "aaaa", Entities: []MessageEntity{{Type: "bold", Offset: 0, Length: 4}}},
+		{Text: "bbbb", Entities: []MessageEntity{{Type: "bold", Offset: 0, Length: 4}}},
+	}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Fatalf("unexpected chunks %+v", chunks)
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	paragraph := strings.Repeat("a", 3000)
+	msg := NewMessage(1, "<b>"+paragraph+"</b>\n\n"+paragraph)
+	msg.ParseMode = ModeHTML
+	msg.ReplyMarkup = NewRemoveKeyboard(true)
+	msg.ReplyParameters.MessageID = 5
+
+	messages, err := SplitMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("unexpected number of messages %d", len(messages))
+	}
+
+	first, last := messages[0], messages[1]
+	if first.Text != paragraph || first.ParseMode != "" ||
+		!reflect.DeepEqual(first.Entities, []MessageEntity{{Type: "bold", Offset: 0, Length: 3000}}) {
+		t.Errorf("unexpected first message %q %v", first.ParseMode, first.Entities)
+	}
+	if last.Text != paragraph || len(last.Entities) != 0 {
+		t.Errorf("unexpected last message entities %v", last.Entities)
+	}
+	if first.ReplyMarkup != nil || last.ReplyMarkup == nil {
+		t.Error("expected the reply markup on the last message only")
+	}
+	if first.ReplyParameters.MessageID != 5 || last.ReplyParameters.MessageID != 0 {
+		t.Error("expected the reply parameters on the first message only")
+	}
+
+	short := NewMessage(1, "*short*")
+	short.ParseMode = ModeMarkdown
+	if messages, err := SplitMessage(short); err != nil || !reflect.DeepEqual(messages, []MessageConfig{short}) {
+		t.Fatalf("unexpected split of a short message %v %v", messages, err)
+	}
+}
+
+func TestSplitCaption(t *testing.T) {
+	photo := NewPhoto(1, FileID("photo"))
+	photo.Caption = strings.Repeat("a ", 1000)
+	photo.ReplyMarkup = NewRemoveKeyboard(true)
+
+	configs, err := SplitCaption(photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("unexpected number of configs %d", len(configs))
+	}
+
+	first, ok := configs[0].(PhotoConfig)
+	if !ok || UTF16Len(first.Caption) > MaxCaptionLength || first.ReplyMarkup != nil {
+		t.Fatalf("unexpected photo %#v", configs[0])
+	}
+	last, ok := configs[1].(MessageConfig)
+	if !ok || last.ChatConfig.ChatID != 1 || last.ReplyMarkup == nil {
+		t.Fatalf("unexpected message %#v", configs[1])
+	}
+	if first.Caption+" "+last.Text != photo.Caption {
+		t.Fatal("caption parts don't add up to the caption")
+	}
+
+	if _, err := SplitCaption(NewSticker(1, FileID("sticker"))); err == nil {
+		t.Fatal("expected an error for a config without a caption")
+	}
+}
+
+func TestSendSplit(t *testing.T) {
+	var texts []string
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			if err := req.ParseForm(); err != nil {
+				return nil, err
+			}
+			texts = append(texts, req.PostForm.Get("text"))
+			return resultResponse(`{"message_id":` + strconv.Itoa(len(texts)) + `}`), nil
+		},
+	})
+
+	messages, err := bot.SendSplit(NewMessage(1, strings.Repeat("a", 4096)+"\n"+strings.Repeat("b", 10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[1].MessageID != 2 {
+		t.Fatalf("unexpected messages %#v", messages)
+	}
+	if len(texts) != 2 || texts[1] != strings.Repeat("b", 10) {
+		t.Fatalf("unexpected requests %q", texts)
+	}
+}
@@ -0,0 +1,150 @@
+package tgbotapi
+
+import (
+	"strings"
+)
+
+// TextBuilder composes formatted text as plain text with entities, so no
+// parse mode or escaping is needed.
+//
+// The zero value is ready to use. Methods return the builder to allow
+// chaining:
+//
+//	var text TextBuilder
+//	text.Bold("Hello").Text(", ").Link("world", "https://telegram.org")
+//	msg := NewFormattedMessage(chatID, &text)
+//
+// The result can also be used for captions with String and Entities, or
+// rendered as markup with HTML and MarkdownV2.
+type TextBuilder struct {
+	text     strings.Builder
+	length   int
+	entities []MessageEntity
+}
+
+// Text appends plain text.
+func (b *TextBuilder) Text(text string) *TextBuilder {
+	b.text.WriteString(text)
+	b.length += UTF16Len(text)
+	return b
+}
+
+// Bold appends bold text.
+func (b *TextBuilder) Bold(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "bold"}, text)
+}
+
+// Italic appends italic text.
+func (b *TextBuilder) Italic(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "italic"}, text)
+}
+
+// Underline appends underlined text.
+func (b *TextBuilder) Underline(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "underline"}, text)
+}
+
+// Strikethrough appends strikethrough text.
+func (b *TextBuilder) Strikethrough(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "strikethrough"}, text)
+}
+
+// Spoiler appends text hidden as a spoiler.
+func (b *TextBuilder) Spoiler(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "spoiler"}, text)
+}
+
+// Code appends monowidth text.
+func (b *TextBuilder) Code(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "code"}, text)
+}
+
+// Pre appends a monowidth block. The language is optional.
+func (b *TextBuilder) Pre(text, language string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "pre", Language: language}, text)
+}
+
+// Link appends text linking to url.
+func (b *TextBuilder) Link(text, url string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "text_link", URL: url}, text)
+}
+
+// Mention appends text mentioning the user with the given ID, which works for
+// users without a username.
+func (b *TextBuilder) Mention(text string, userID int64) *TextBuilder {
+	return b.styled(MessageEntity{Type: "text_mention", User: &User{ID: userID}}, text)
+}
+
+// CustomEmoji appends a custom emoji. The emoji is shown where custom emoji
+// are not supported.
+func (b *TextBuilder) CustomEmoji(emoji, customEmojiID string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "custom_emoji", CustomEmojiID: customEmojiID}, emoji)
+}
+
+// DateTime appends text showing the given Unix time in the format of the
+// user. The format is optional.
+func (b *TextBuilder) DateTime(text string, unixTime int64, format string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "date_time", UnixTime: unixTime, DateTimeFormat: format}, text)
+}
+
+// Blockquote appends a block quotation.
+func (b *TextBuilder) Blockquote(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "blockquote"}, text)
+}
+
+// ExpandableBlockquote appends a block quotation collapsed by default.
+func (b *TextBuilder) ExpandableBlockquote(text string) *TextBuilder {
+	return b.styled(MessageEntity{Type: "expandable_blockquote"}, text)
+}
+
+// Nest appends the text built by build formatted with entity, allowing
+// entities to be combined. The offset and length of entity are set by Nest.
+//
+//	text.Nest(MessageEntity{Type: "bold"}, func(b *TextBuilder) {
+//		b.Text("bold and ").Italic("italic")
+//	})
+func (b *TextBuilder) Nest(entity MessageEntity, build func(b *TextBuilder)) *TextBuilder {
+	start, index := b.length, len(b.entities)
+	build(b)
+
+	entity.Offset = start
+	entity.Length = b.length - start
+	if entity.Length > 0 {
+		b.entities = append(b.entities, MessageEntity{})
+		copy(b.entities[index+1:], b.entities[index:])
+		b.entities[index] = entity
+	}
+	return b
+}
+
+func (b *TextBuilder) styled(entity MessageEntity, text string) *TextBuilder {
+	return b.Nest(entity, func(b *TextBuilder) {
+		b.Text(text)
+	})
+}
+
+// String returns the plain text.
+func (b *TextBuilder) String() string {
+	return b.text.String()
+}
+
+// Entities returns the entities of the text ordered by offset.
+func (b *TextBuilder) Entities() []MessageEntity {
+	return append([]MessageEntity(nil), b.entities...)
+}
+
+// Len returns the length of the text in UTF-16 code units, the unit used by
+// Telegram for message length limits.
+func (b *TextBuilder) Len() int {
+	return b.length
+}
+
+// HTML returns the text formatted for ModeHTML.
+func (b *TextBuilder) HTML() string {
+	return renderMarkup(b.String(), b.entities, ModeHTML)
+}
+
+// MarkdownV2 returns the text formatted for ModeMarkdownV2.
+func (b *TextBuilder) MarkdownV2() string {
+	return renderMarkup(b.String(), b.entities, ModeMarkdownV2)
+}
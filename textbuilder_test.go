@@ -0,0 +1,102 @@
+package tgbotapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTextBuilderEntities(t *testing.T) {
+	var text TextBuilder
+	text.Text("👋 ").
+		Bold("Hello").
+		Text(", ").
+		Nest(MessageEntity{Type: "text_link", URL: "https://telegram.org"}, func(b *TextBuilder) {
+			b.Text("the ").Italic("world")
+		}).
+		Text("!").
+		Code("").
+		Mention("you", 42)
+
+	if text.String() != "👋 Hello, the world!you" {
+		t.Fatalf("unexpected text %q", text.String())
+	}
+	if text.Len() != 23 {
+		t.Fatalf("unexpected length %d", text.Len())
+	}
+
+	expected := []MessageEntity{
+		{Type: "bold", Offset: 3, Length: 5},
+		{Type: "text_link", Offset: 10, Length: 9, URL: "https://telegram.org"},
+		{Type: "italic", Offset: 14, Length: 5},
+		{Type: "text_mention", Offset: 20, Length: 3, User: &User{ID: 42}},
+	}
+	if !reflect.DeepEqual(text.Entities(), expected) {
+		t.Fatalf("unexpected entities %#v", text.Entities())
+	}
+
+	for _, entity := range text.Entities() {
+		if entity.Type == "text_link" && entity.Text(text.String()) != "the world" {
+			t.Fatalf("entity offsets do not match the text")
+		}
+	}
+}
+
+func TestTextBuilderHTML(t *testing.T) {
+	var text TextBuilder
+	text.Text("1 < 2 & ").
+		Bold("bold").
+		Text(" ").
+		Link("link", `https://example.com/?a=1&b="2"`).
+		Text(" ").
+		Pre("fmt.Println(\"<hi>\")", "go").
+		ExpandableBlockquote("quote").
+		CustomEmoji("👍", "123")
+
+	expected := `1 &lt; 2 &amp; <b>bold</b> <a href="https://example.com/?a=1&amp;b=&#34;2&#34;">link</a> ` +
+		`<pre><code class="language-go">fmt.Println("&lt;hi&gt;")</code></pre>` +
+		`<blockquote expandable>quote</blockquote><tg-emoji emoji-id="123">👍</tg-emoji>`
+	if html := text.HTML(); html != expected {
+		t.Fatalf("unexpected HTML\n%s\n%s", html, expected)
+	}
+}
+
+func TestTextBuilderMarkdownV2(t *testing.T) {
+	var text TextBuilder
+	text.Text("1+1=2. ").
+		Bold("bold!").
+		Text(" ").
+		Link("link", "https://example.com/(x)").
+		Text(" ").
+		Code("a`b\\c").
+		Text(" ").
+		Nest(MessageEntity{Type: "underline"}, func(b *TextBuilder) {
+			b.Italic("both")
+		}).
+		Text("\n").
+		Blockquote("line 1\nline 2").
+		Text("\n").
+		Spoiler("secret")
+
+	expected := "1\\+1\\=2\\. *bold\\!* [link](https://example.com/(x\\)) `a\\`b\\\\c` __\r_both_\r__\n>line 1\n>line 2\n||secret||"
+	if markdown := text.MarkdownV2(); markdown != expected {
+		t.Fatalf("unexpected MarkdownV2\n%q\n%q", markdown, expected)
+	}
+}
+
+func TestNewFormattedMessage(t *testing.T) {
+	var text TextBuilder
+	text.Bold("Hi")
+
+	msg := NewFormattedMessage(1, &text)
+	if msg.Text != "Hi" || msg.ParseMode != "" || len(msg.Entities) != 1 {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+
+	params, err := msg.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["entities"] != `[{"type":"bold","offset":0,"length":2}]` {
+		t.Fatalf("unexpected entities param %q", params["entities"])
+	}
+}
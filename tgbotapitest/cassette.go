@@ -0,0 +1,386 @@
+package tgbotapitest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/OvyFlash/telegram-bot-api"
+)
+
+// Interaction is a recorded request and its response. Cassette files hold
+// one JSON encoded Interaction per line.
+type Interaction struct {
+	// URL is the URL of the request. The bot token is replaced by "<token>"
+	// in the URL, the parameters and the response.
+	URL string `json:"url"`
+	// Method is the Bot API method name, such as "sendMessage". It is empty
+	// for file downloads.
+	Method string `json:"method,omitempty"`
+	// Params are the request parameters.
+	Params map[string]string `json:"params,omitempty"`
+	// Files describe the files uploaded with the request.
+	Files []RecordedFile `json:"files,omitempty"`
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"status_code"`
+	// Response is the response body if it is JSON, as Bot API responses are.
+	Response json.RawMessage `json:"response,omitempty"`
+	// Body is the response body if it isn't JSON, such as a downloaded file.
+	Body []byte `json:"body,omitempty"`
+}
+
+// RecordedFile describes an uploaded file. Its content isn't recorded.
+type RecordedFile struct {
+	Field    string `json:"field"`
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// Recorder is an HTTPClient recording every request made through it and the
+// response to a cassette file, to be served back by a Replayer.
+//
+// Request bodies, including uploaded files, are read into memory before they
+// are sent.
+type Recorder struct {
+	client tgbotapi.HTTPClient
+	token  string
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error
+}
+
+// NewRecorder creates a Recorder sending requests with client and appending
+// them to the cassette file at path, with token redacted. A nil client uses
+// http.DefaultClient.
+func NewRecorder(client tgbotapi.HTTPClient, token, path string) (*Recorder, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+
+	return &Recorder{
+		client:  client,
+		token:   token,
+		file:    file,
+		encoder: encoder,
+	}, nil
+}
+
+// Do sends the request and records it with its response. Errors recording
+// it don't fail the request and are returned by Close.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	interaction, body, err := newInteraction(req, r.token)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction.StatusCode = resp.StatusCode
+	if json.Valid(respBody) {
+		interaction.Response = redactBytes(bytes.TrimSpace(respBody), r.token)
+	} else {
+		interaction.Body = respBody
+	}
+	r.record(interaction)
+
+	return resp, nil
+}
+
+func (r *Recorder) record(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	r.err = r.encoder.Encode(interaction)
+}
+
+// Close closes the cassette file and returns the first error recording a
+// request.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return errors.Join(r.err, r.file.Close())
+}
+
+// Replayer is an HTTPClient serving the responses recorded by a Recorder.
+//
+// Requests must be made in the recorded order with the same parameters and
+// files, otherwise Do returns an error describing the difference.
+type Replayer struct {
+	token string
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewReplayer loads the cassette file at path. Requests are compared with
+// token replaced by "<token>", and "<token>" in the recorded responses is
+// replaced by token, so the bot replaying them may use a different token.
+func NewReplayer(token, path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Replayer{token: token, interactions: interactions}, nil
+}
+
+// Do returns the response recorded for the next request.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	got, _, err := newInteraction(req, r.token)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.interactions) == 0 {
+		return nil, fmt.Errorf("replay %s: no more recorded requests", got.URL)
+	}
+	want := r.interactions[0]
+	if err := compareInteractions(want, got); err != nil {
+		return nil, fmt.Errorf("replay %s: %w", got.URL, err)
+	}
+	r.interactions = r.interactions[1:]
+
+	header := make(http.Header)
+	body := want.Body
+	if want.Response != nil {
+		header.Set("Content-Type", "application/json")
+		body = unredactBytes(want.Response, r.token)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", want.StatusCode, http.StatusText(want.StatusCode)),
+		StatusCode:    want.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of recorded requests that weren't made yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.interactions)
+}
+
+// compareInteractions reports how a request differs from the recorded one.
+// The host of the URLs isn't compared.
+func compareInteractions(want, got Interaction) error {
+	if wantPath, gotPath := urlPath(want.URL), urlPath(got.URL); wantPath != gotPath {
+		return fmt.Errorf("expected request to %s", wantPath)
+	}
+	for _, key := range slices.Sorted(maps.Keys(want.Params)) {
+		if value, ok := got.Params[key]; !ok || value != want.Params[key] {
+			return fmt.Errorf("expected %s=%q, got %q", key, want.Params[key], value)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(got.Params)) {
+		if _, ok := want.Params[key]; !ok {
+			return fmt.Errorf("unexpected parameter %s=%q", key, got.Params[key])
+		}
+	}
+	if !slices.Equal(want.Files, got.Files) {
+		return fmt.Errorf("expected files %+v, got %+v", want.Files, got.Files)
+	}
+	return nil
+}
+
+func urlPath(rawURL string) string {
+	_, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return rawURL
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[i:]
+	}
+	return ""
+}
+
+const redactedToken = "<token>"
+
+func redact(s, token string) string {
+	if token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, token, redactedToken)
+}
+
+func redactBytes(data []byte, token string) []byte {
+	if token == "" {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte(token), []byte(redactedToken))
+}
+
+func unredactBytes(data []byte, token string) []byte {
+	if token == "" {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte(redactedToken), []byte(token))
+}
+
+// newInteraction records a request with token redacted. It returns the
+// request body if it was read.
+func newInteraction(req *http.Request, token string) (Interaction, []byte, error) {
+	redacted := redact(req.URL.Path, token)
+	interaction := Interaction{URL: req.URL.Scheme + "://" + req.URL.Host + redacted}
+	if req.URL.RawQuery != "" {
+		interaction.URL += "?" + redact(req.URL.RawQuery, token)
+	}
+
+	if !strings.HasPrefix(redacted, "/file/") {
+		interaction.Method = path.Base(redacted)
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return interaction, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return interaction, nil, err
+	}
+
+	mediaType, mediaParams, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return interaction, nil, err
+		}
+		interaction.Params = flattenValues(values)
+	case "multipart/form-data":
+		if err := readMultipartInteraction(&interaction, body, mediaParams["boundary"]); err != nil {
+			return interaction, nil, err
+		}
+	}
+	for key, value := range interaction.Params {
+		interaction.Params[key] = redact(value, token)
+	}
+
+	return interaction, body, nil
+}
+
+func readMultipartInteraction(interaction *Interaction, body []byte, boundary string) error {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	values := make(url.Values)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return err
+			}
+			values.Add(part.FormName(), string(value))
+			continue
+		}
+
+		hash := sha256.New()
+		size, err := io.Copy(hash, part)
+		if err != nil {
+			return err
+		}
+		interaction.Files = append(interaction.Files, RecordedFile{
+			Field:    part.FormName(),
+			FileName: part.FileName(),
+			Size:     size,
+			SHA256:   hex.EncodeToString(hash.Sum(nil)),
+		})
+	}
+
+	interaction.Params = flattenValues(values)
+	slices.SortFunc(interaction.Files, func(a, b RecordedFile) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return nil
+}
+
+func flattenValues(values url.Values) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(values))
+	for key := range values {
+		params[key] = values.Get(key)
+	}
+	return params
+}
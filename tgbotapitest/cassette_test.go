@@ -0,0 +1,186 @@
+package tgbotapitest
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/OvyFlash/telegram-bot-api"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	server := NewServer("123:secret")
+	defer server.Close()
+
+	recorder, err := NewRecorder(server.server.Client(), "123:secret", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot, err := server.NewBot(tgbotapi.WithHTTPClient(recorder), tgbotapi.WithLoggingDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := exerciseBot(t, bot)
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cassette, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(cassette, []byte("secret")) {
+		t.Fatalf("cassette contains the token:\n%s", cassette)
+	}
+	if !bytes.Contains(cassette, []byte(`/bot<token>/sendPhoto`)) || !bytes.Contains(cassette, []byte(`"file_name":"photo.jpg"`)) {
+		t.Fatalf("unexpected cassette:\n%s", cassette)
+	}
+
+	replayer, err := NewReplayer("456:other", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot, err = tgbotapi.NewBotAPIWithOptions("456:other",
+		tgbotapi.WithAPIEndpoint("https://example.com/bot%s/%s"),
+		tgbotapi.WithFileEndpoint("https://example.com/file/bot%s/%s"),
+		tgbotapi.WithHTTPClient(replayer),
+		tgbotapi.WithLoggingDisabled(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := exerciseBot(t, bot); replayed != recorded {
+		t.Fatalf("replayed %q, recorded %q", replayed, recorded)
+	}
+	if remaining := replayer.Remaining(); remaining != 0 {
+		t.Fatalf("expected all requests to be replayed, %d remaining", remaining)
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(1, "more")); err == nil || !strings.Contains(err.Error(), "no more recorded requests") {
+		t.Fatalf("expected an error after the cassette, got %v", err)
+	}
+}
+
+func TestRecorderRedactsToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	server := NewServer("123:secret")
+	defer server.Close()
+
+	recorder, err := NewRecorder(server.server.Client(), "123:secret", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot, err := server.NewBot(tgbotapi.WithHTTPClient(recorder), tgbotapi.WithLoggingDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := tgbotapi.NewWebhook("https://example.com/hook/123:secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Request(webhook); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.GetWebhookInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cassette, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(cassette, []byte("secret")) {
+		t.Fatalf("cassette contains the token:\n%s", cassette)
+	}
+	if !bytes.Contains(cassette, []byte(`"url":"https://example.com/hook/<token>"`)) {
+		t.Fatalf("webhook URL was not redacted:\n%s", cassette)
+	}
+
+	// The replaying bot gets its own token back.
+	replayer, err := NewReplayer("456:other", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot, err = tgbotapi.NewBotAPIWithOptions("456:other", tgbotapi.WithHTTPClient(replayer), tgbotapi.WithLoggingDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook, err = tgbotapi.NewWebhook("https://example.com/hook/456:other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Request(webhook); err != nil {
+		t.Fatal(err)
+	}
+	info, err := bot.GetWebhookInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.URL != "https://example.com/hook/456:other" {
+		t.Fatalf("unexpected webhook URL %q", info.URL)
+	}
+}
+
+func TestRecorderRedactsOnlyToken(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/file/bot123:secret/bot_photos/file.jpg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interaction, _, err := newInteraction(req, "123:secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interaction.URL != "https://example.com/file/bot<token>/bot_photos/file.jpg" {
+		t.Fatalf("unexpected URL %q", interaction.URL)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	line := `{"url":"https://api.telegram.org/bot<token>/sendMessage","method":"sendMessage","params":{"chat_id":"1","text":"hello"},"status_code":200,"response":{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewReplayer("token", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := &tgbotapi.BotAPI{Token: "token", Client: replayer}
+	bot.SetAPIEndpoint(tgbotapi.APIEndpoint)
+
+	if _, err := bot.Send(tgbotapi.NewMessage(1, "changed")); err == nil || !strings.Contains(err.Error(), `expected text="hello", got "changed"`) {
+		t.Fatalf("expected a mismatch error, got %v", err)
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(1, "hello")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// exerciseBot sends a message and a photo and downloads the photo.
+func exerciseBot(t *testing.T, bot *tgbotapi.BotAPI) string {
+	t.Helper()
+
+	message, err := bot.Send(tgbotapi.NewMessage(1, "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	photo, err := bot.Send(tgbotapi.NewPhoto(1, tgbotapi.FileBytes{Name: "photo.jpg", Bytes: []byte("image")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var content bytes.Buffer
+	if _, err := bot.DownloadFile(photo.Photo[0].FileID, &content, tgbotapi.DownloadConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return message.Text + " " + photo.Photo[0].FileID + " " + content.String()
+}
@@ -0,0 +1,707 @@
+package tgbotapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/OvyFlash/telegram-bot-api"
+)
+
+type methodHandler func(s *Server, r *http.Request, req *Request) (any, error)
+
+// methods are the implemented Bot API methods by lowercase name.
+var methods = map[string]methodHandler{
+	"getme":                  (*Server).getMe,
+	"sendmessage":            (*Server).sendMessage,
+	"sendphoto":              mediaSender("photo"),
+	"sendaudio":              mediaSender("audio"),
+	"senddocument":           mediaSender("document"),
+	"sendvideo":              mediaSender("video"),
+	"sendanimation":          mediaSender("animation"),
+	"sendvoice":              mediaSender("voice"),
+	"sendvideonote":          mediaSender("video_note"),
+	"sendsticker":            mediaSender("sticker"),
+	"sendmediagroup":         (*Server).sendMediaGroup,
+	"sendchataction":         (*Server).sendChatAction,
+	"editmessagetext":        (*Server).editMessageText,
+	"editmessagecaption":     (*Server).editMessageCaption,
+	"editmessagemedia":       (*Server).editMessageMedia,
+	"editmessagereplymarkup": (*Server).editMessageReplyMarkup,
+	"deletemessage":          (*Server).deleteMessage,
+	"getupdates":             (*Server).getUpdates,
+	"setwebhook":             (*Server).setWebhook,
+	"deletewebhook":          (*Server).deleteWebhook,
+	"getwebhookinfo":         (*Server).getWebhookInfo,
+	"getfile":                (*Server).getFile,
+}
+
+func (s *Server) getMe(_ *http.Request, _ *Request) (any, error) {
+	return s.Bot, nil
+}
+
+func (s *Server) sendMessage(_ *http.Request, req *Request) (any, error) {
+	chatID, err := chatIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Params.Get("text") == "" {
+		return nil, badRequest("message text is empty")
+	}
+
+	message, err := newMessage(req)
+	if err != nil {
+		return nil, err
+	}
+	if message.Text, message.Entities, err = formattedText(req, "text", "entities"); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sendNewMessage(chatID, req, message), nil
+}
+
+// mediaSender returns the handler of a method sending the file in field.
+func mediaSender(field string) methodHandler {
+	return func(s *Server, _ *http.Request, req *Request) (any, error) {
+		chatID, err := chatIDParam(req)
+		if err != nil {
+			return nil, err
+		}
+
+		message, err := newMessage(req)
+		if err != nil {
+			return nil, err
+		}
+		if field != "sticker" && field != "video_note" {
+			if message.Caption, message.CaptionEntities, err = formattedText(req, "caption", "caption_entities"); err != nil {
+				return nil, err
+			}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		file, err := s.requestFile(req, field)
+		if err != nil {
+			return nil, err
+		}
+		setMessageFile(&message, field, file)
+
+		return s.sendNewMessage(chatID, req, message), nil
+	}
+}
+
+// inputMedia is an item of the media sent by sendMediaGroup and
+// editMessageMedia.
+type inputMedia struct {
+	Type            string                   `json:"type"`
+	Media           string                   `json:"media"`
+	Caption         string                   `json:"caption"`
+	ParseMode       string                   `json:"parse_mode"`
+	CaptionEntities []tgbotapi.MessageEntity `json:"caption_entities"`
+}
+
+// mediaTypes are the supported media types by whether they can be sent in
+// a media group.
+var mediaTypes = map[string]bool{
+	"photo":     true,
+	"video":     true,
+	"audio":     true,
+	"document":  true,
+	"animation": false,
+}
+
+func (s *Server) sendMediaGroup(_ *http.Request, req *Request) (any, error) {
+	chatID, err := chatIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var media []inputMedia
+	if err := jsonParam(req, "media", &media); err != nil {
+		return nil, err
+	}
+	if len(media) < 2 || len(media) > 10 {
+		return nil, badRequest("media group must include 2-10 items")
+	}
+
+	messages := make([]tgbotapi.Message, len(media))
+	for i, item := range media {
+		if !mediaTypes[item.Type] {
+			return nil, badRequest("can't send %s in a media group", item.Type)
+		}
+		if messages[i], err = newMessage(req); err != nil {
+			return nil, err
+		}
+		if messages[i].Caption, messages[i].CaptionEntities, err = formatText(item.Caption, item.ParseMode, item.CaptionEntities); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := make([]*storedFile, len(media))
+	for i, item := range media {
+		if files[i], err = s.inputFile(req, item.Type, item.Media); err != nil {
+			return nil, err
+		}
+	}
+
+	groupID := fmt.Sprintf("%d-%d", chatID, s.chat(chatID).nextMessageID)
+	for i := range messages {
+		setMessageFile(&messages[i], media[i].Type, files[i])
+		messages[i].MediaGroupID = groupID
+		messages[i] = s.sendNewMessage(chatID, req, messages[i])
+	}
+	return messages, nil
+}
+
+func (s *Server) sendChatAction(_ *http.Request, req *Request) (any, error) {
+	if _, err := chatIDParam(req); err != nil {
+		return nil, err
+	}
+	if req.Params.Get("action") == "" {
+		return nil, badRequest("action is empty")
+	}
+	return true, nil
+}
+
+func (s *Server) editMessageText(_ *http.Request, req *Request) (any, error) {
+	text, entities, err := formattedText(req, "text", "entities")
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, badRequest("message text is empty")
+	}
+
+	return s.editMessage(req, func(message *tgbotapi.Message) error {
+		if message.Text == "" {
+			return badRequest("there is no text in the message to edit")
+		}
+		message.Text, message.Entities = text, entities
+		return nil
+	})
+}
+
+func (s *Server) editMessageCaption(_ *http.Request, req *Request) (any, error) {
+	caption, entities, err := formattedText(req, "caption", "caption_entities")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editMessage(req, func(message *tgbotapi.Message) error {
+		if message.Text != "" {
+			return badRequest("there is no caption in the message to edit")
+		}
+		message.Caption, message.CaptionEntities = caption, entities
+		return nil
+	})
+}
+
+func (s *Server) editMessageMedia(_ *http.Request, req *Request) (any, error) {
+	var media inputMedia
+	if err := jsonParam(req, "media", &media); err != nil {
+		return nil, err
+	}
+	if _, ok := mediaTypes[media.Type]; !ok {
+		return nil, badRequest("unsupported media type %q", media.Type)
+	}
+	caption, entities, err := formatText(media.Caption, media.ParseMode, media.CaptionEntities)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.editMessage(req, func(message *tgbotapi.Message) error {
+		if message.Text != "" {
+			return badRequest("there is no media in the message to edit")
+		}
+		file, err := s.inputFile(req, media.Type, media.Media)
+		if err != nil {
+			return err
+		}
+
+		clearMessageFile(message)
+		setMessageFile(message, media.Type, file)
+		message.Caption, message.CaptionEntities = caption, entities
+		return nil
+	})
+}
+
+func (s *Server) editMessageReplyMarkup(_ *http.Request, req *Request) (any, error) {
+	return s.editMessage(req, func(*tgbotapi.Message) error {
+		return nil
+	})
+}
+
+// editMessage applies edit and the reply markup of the request to the
+// message identified by the request. edit is called with s.mu held. Inline
+// messages aren't stored, so editing them always succeeds.
+func (s *Server) editMessage(req *Request, edit func(*tgbotapi.Message) error) (any, error) {
+	markup, err := replyMarkupParam(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Params.Get("inline_message_id") != "" {
+		return true, nil
+	}
+
+	chatID, err := chatIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := intParam(req, "message_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok || state.messages[messageID] == nil {
+		return nil, badRequest("message to edit not found")
+	}
+	message := *state.messages[messageID]
+
+	if err := edit(&message); err != nil {
+		return nil, err
+	}
+	message.ReplyMarkup = markup
+
+	if reflect.DeepEqual(*state.messages[messageID], message) {
+		return nil, badRequest("message is not modified: specified new message content and reply markup are exactly the same as a current content and reply markup of the message")
+	}
+
+	message.EditDate = time.Now().Unix()
+	state.messages[messageID] = &message
+	return message, nil
+}
+
+func (s *Server) deleteMessage(_ *http.Request, req *Request) (any, error) {
+	chatID, err := chatIDParam(req)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := intParam(req, "message_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok || state.messages[messageID] == nil {
+		return nil, badRequest("message to delete not found")
+	}
+	delete(state.messages, messageID)
+	return true, nil
+}
+
+// getUpdates returns the queued updates, waiting up to the timeout of the
+// request for new ones if none are queued.
+func (s *Server) getUpdates(r *http.Request, req *Request) (any, error) {
+	offset, err := optionalIntParam(req, "offset")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := optionalIntParam(req, "limit")
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	timeout, err := optionalIntParam(req, "timeout")
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.After(time.Duration(timeout) * time.Second)
+
+	for {
+		s.mu.Lock()
+		if s.webhook.URL != "" {
+			s.mu.Unlock()
+			return nil, &apiError{
+				code:        http.StatusConflict,
+				description: "Conflict: can't use getUpdates method while webhook is active; use deleteWebhook to delete the webhook first",
+			}
+		}
+
+		s.confirmUpdates(offset)
+		updates := append([]tgbotapi.Update{}, s.updates[:min(limit, len(s.updates))]...)
+		added := s.updatesAdded
+		s.mu.Unlock()
+
+		if len(updates) > 0 || timeout <= 0 {
+			return updates, nil
+		}
+
+		select {
+		case <-added:
+		case <-deadline:
+			timeout = 0
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+	}
+}
+
+// confirmUpdates forgets the updates before offset. A negative offset keeps
+// only the last -offset updates.
+func (s *Server) confirmUpdates(offset int) {
+	switch {
+	case offset > 0:
+		i := 0
+		for i < len(s.updates) && s.updates[i].UpdateID < offset {
+			i++
+		}
+		s.updates = s.updates[i:]
+	case offset < 0:
+		s.updates = s.updates[max(0, len(s.updates)+offset):]
+	}
+}
+
+func (s *Server) setWebhook(_ *http.Request, req *Request) (any, error) {
+	webhookURL := req.Params.Get("url")
+	if webhookURL != "" && !strings.HasPrefix(webhookURL, "https://") {
+		return nil, badRequest("bad webhook: An HTTPS URL must be provided for webhook")
+	}
+
+	maxConnections, err := optionalIntParam(req, "max_connections")
+	if err != nil {
+		return nil, err
+	}
+	var allowedUpdates []string
+	if err := jsonParam(req, "allowed_updates", &allowedUpdates); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhook = tgbotapi.WebhookInfo{
+		URL:            webhookURL,
+		MaxConnections: maxConnections,
+		AllowedUpdates: allowedUpdates,
+		IPAddress:      req.Params.Get("ip_address"),
+	}
+	for _, file := range req.Files {
+		if file.Field == "certificate" {
+			s.webhook.HasCustomCertificate = true
+		}
+	}
+	s.dropPendingUpdates(req)
+	return true, nil
+}
+
+func (s *Server) deleteWebhook(_ *http.Request, req *Request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhook = tgbotapi.WebhookInfo{}
+	s.dropPendingUpdates(req)
+	return true, nil
+}
+
+func (s *Server) dropPendingUpdates(req *Request) {
+	if req.Params.Get("drop_pending_updates") == "true" {
+		s.updates = nil
+	}
+}
+
+func (s *Server) getWebhookInfo(_ *http.Request, _ *Request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.webhook
+	info.PendingUpdateCount = len(s.updates)
+	return info, nil
+}
+
+func (s *Server) getFile(_ *http.Request, req *Request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[req.Params.Get("file_id")]
+	if !ok {
+		return nil, badRequest("invalid file_id")
+	}
+	return file.file, nil
+}
+
+// addUpdate queues update. The caller must hold s.mu.
+func (s *Server) addUpdate(update tgbotapi.Update) tgbotapi.Update {
+	if update.UpdateID == 0 {
+		update.UpdateID = s.nextUpdateID
+	}
+	s.nextUpdateID = max(s.nextUpdateID, update.UpdateID) + 1
+	s.updates = append(s.updates, update)
+
+	close(s.updatesAdded)
+	s.updatesAdded = make(chan struct{})
+	return update
+}
+
+// chat returns the state of a chat, creating it on first use. The caller
+// must hold s.mu.
+func (s *Server) chat(chatID int64) *chatState {
+	state, ok := s.chats[chatID]
+	if !ok {
+		chatType := "private"
+		switch {
+		case chatID < -1_000_000_000_000:
+			chatType = "supergroup"
+		case chatID < 0:
+			chatType = "group"
+		}
+
+		state = &chatState{
+			chat:          tgbotapi.Chat{ID: chatID, Type: chatType},
+			messages:      make(map[int]*tgbotapi.Message),
+			nextMessageID: 1,
+		}
+		s.chats[chatID] = state
+	}
+	return state
+}
+
+// storeMessage stores message in a chat with the next message ID. The caller
+// must hold s.mu.
+func (s *Server) storeMessage(chatID int64, message tgbotapi.Message) tgbotapi.Message {
+	state := s.chat(chatID)
+
+	message.MessageID = state.nextMessageID
+	state.nextMessageID++
+	message.Chat = state.chat
+	if message.Date == 0 {
+		message.Date = time.Now().Unix()
+	}
+
+	state.messages[message.MessageID] = &message
+	return message
+}
+
+// sendNewMessage stores a message sent by the bot. The caller must hold
+// s.mu.
+func (s *Server) sendNewMessage(chatID int64, req *Request, message tgbotapi.Message) tgbotapi.Message {
+	bot := s.Bot
+	message.From = &bot
+
+	var reply tgbotapi.ReplyParameters
+	if jsonParam(req, "reply_parameters", &reply) == nil && reply.MessageID != 0 {
+		if replied, ok := s.chat(chatID).messages[reply.MessageID]; ok {
+			repliedCopy := *replied
+			message.ReplyToMessage = &repliedCopy
+		}
+	}
+
+	return s.storeMessage(chatID, message)
+}
+
+// requestFile returns the file sent in field, storing it if it was uploaded
+// or given by URL. The caller must hold s.mu.
+func (s *Server) requestFile(req *Request, field string) (*storedFile, error) {
+	for _, file := range req.Files {
+		if file.Field == field {
+			return s.storeFile(field+"s", file.FileName, file.Data), nil
+		}
+	}
+
+	value := req.Params.Get(field)
+	if value == "" {
+		return nil, badRequest("there is no %s in the request", field)
+	}
+	return s.inputFile(req, field, value)
+}
+
+// inputFile returns the file given by value, which is a file ID, a URL or
+// "attach://<name>" for the file uploaded as name, storing it as a file of
+// type field if it is new. The caller must hold s.mu.
+func (s *Server) inputFile(req *Request, field, value string) (*storedFile, error) {
+	if name, ok := strings.CutPrefix(value, "attach://"); ok {
+		for _, file := range req.Files {
+			if file.Field == name {
+				return s.storeFile(field+"s", file.FileName, file.Data), nil
+			}
+		}
+		return nil, badRequest("file %s not found in the request", name)
+	}
+	if file, ok := s.files[value]; ok {
+		return file, nil
+	}
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return s.storeFile(field+"s", path.Base(value), nil), nil
+	}
+	return nil, badRequest("wrong file identifier/HTTP URL specified")
+}
+
+// storeFile stores a file in dir with a new file ID. The caller must hold
+// s.mu.
+func (s *Server) storeFile(dir, name string, data []byte) *storedFile {
+	s.nextFileID++
+	file := &storedFile{
+		file: tgbotapi.File{
+			FileID:       fmt.Sprintf("file-%d", s.nextFileID),
+			FileUniqueID: fmt.Sprintf("unique-%d", s.nextFileID),
+			FileSize:     int64(len(data)),
+			FilePath:     fmt.Sprintf("%s/file_%d%s", dir, s.nextFileID, path.Ext(name)),
+		},
+		name: name,
+		data: data,
+	}
+	s.files[file.file.FileID] = file
+	return file
+}
+
+// clearMessageFile removes the file of a message.
+func clearMessageFile(message *tgbotapi.Message) {
+	message.Photo = nil
+	message.Audio = nil
+	message.Document = nil
+	message.Video = nil
+	message.Animation = nil
+	message.Voice = nil
+	message.VideoNote = nil
+	message.Sticker = nil
+}
+
+func setMessageFile(message *tgbotapi.Message, field string, stored *storedFile) {
+	file := stored.file
+	mimeType := mime.TypeByExtension(path.Ext(stored.name))
+
+	switch field {
+	case "photo":
+		message.Photo = []tgbotapi.PhotoSize{{FileID: file.FileID, FileUniqueID: file.FileUniqueID, FileSize: int(file.FileSize)}}
+	case "audio":
+		message.Audio = &tgbotapi.Audio{FileID: file.FileID, FileUniqueID: file.FileUniqueID, FileName: stored.name, MimeType: mimeType, FileSize: file.FileSize}
+	case "document":
+		message.Document = &tgbotapi.Document{FileID: file.FileID, FileUniqueID: file.FileUniqueID, FileName: stored.name, MimeType: mimeType, FileSize: file.FileSize}
+	case "video":
+		message.Video = &tgbotapi.Video{FileID: file.FileID, FileUniqueID: file.FileUniqueID, FileName: stored.name, MimeType: mimeType, FileSize: file.FileSize}
+	case "animation":
+		message.Animation = &tgbotapi.Animation{FileID: file.FileID, FileUniqueID: file.FileUniqueID, FileName: stored.name, MimeType: mimeType, FileSize: file.FileSize}
+	case "voice":
+		message.Voice = &tgbotapi.Voice{FileID: file.FileID, FileUniqueID: file.FileUniqueID, MimeType: mimeType, FileSize: file.FileSize}
+	case "video_note":
+		message.VideoNote = &tgbotapi.VideoNote{FileID: file.FileID, FileUniqueID: file.FileUniqueID, FileSize: int(file.FileSize)}
+	case "sticker":
+		message.Sticker = &tgbotapi.Sticker{FileID: file.FileID, FileUniqueID: file.FileUniqueID, Type: "regular", FileSize: int(file.FileSize)}
+	}
+}
+
+// newMessage returns a message with the common send parameters of the
+// request.
+func newMessage(req *Request) (tgbotapi.Message, error) {
+	var message tgbotapi.Message
+
+	threadID, err := optionalIntParam(req, "message_thread_id")
+	if err != nil {
+		return message, err
+	}
+	message.MessageThreadID = threadID
+
+	message.ReplyMarkup, err = replyMarkupParam(req)
+	return message, err
+}
+
+// formattedText returns the text in the field and its entities, parsed
+// with the parse mode of the request if one is given.
+func formattedText(req *Request, field, entitiesField string) (string, []tgbotapi.MessageEntity, error) {
+	var entities []tgbotapi.MessageEntity
+	if err := jsonParam(req, entitiesField, &entities); err != nil {
+		return "", nil, err
+	}
+	return formatText(req.Params.Get(field), req.Params.Get("parse_mode"), entities)
+}
+
+// formatText returns text and its entities, parsed with parseMode if one is
+// given.
+func formatText(text, parseMode string, entities []tgbotapi.MessageEntity) (string, []tgbotapi.MessageEntity, error) {
+	if parseMode != "" {
+		if parseMode != tgbotapi.ModeHTML && parseMode != tgbotapi.ModeMarkdownV2 {
+			return "", nil, badRequest("unsupported parse_mode")
+		}
+		text, entities, err := tgbotapi.ParseMarkup(text, parseMode)
+		if err != nil {
+			return "", nil, badRequest("%s", err)
+		}
+		return text, nilIfEmpty(entities), nil
+	}
+	return text, nilIfEmpty(entities), nil
+}
+
+func nilIfEmpty(entities []tgbotapi.MessageEntity) []tgbotapi.MessageEntity {
+	if len(entities) == 0 {
+		return nil
+	}
+	return entities
+}
+
+// replyMarkupParam returns the inline keyboard of the request. Other reply
+// markup isn't stored in messages and is ignored.
+func replyMarkupParam(req *Request) (*tgbotapi.InlineKeyboardMarkup, error) {
+	var markup struct {
+		InlineKeyboard [][]tgbotapi.InlineKeyboardButton `json:"inline_keyboard"`
+	}
+	if err := jsonParam(req, "reply_markup", &markup); err != nil {
+		return nil, err
+	}
+	if len(markup.InlineKeyboard) == 0 {
+		return nil, nil
+	}
+	return &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: markup.InlineKeyboard}, nil
+}
+
+func chatIDParam(req *Request) (int64, error) {
+	value := req.Params.Get("chat_id")
+	if value == "" {
+		return 0, badRequest("chat_id is empty")
+	}
+	chatID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || chatID == 0 {
+		return 0, badRequest("chat not found")
+	}
+	return chatID, nil
+}
+
+func intParam(req *Request, name string) (int, error) {
+	if req.Params.Get(name) == "" {
+		return 0, badRequest("%s is empty", name)
+	}
+	return optionalIntParam(req, name)
+}
+
+func optionalIntParam(req *Request, name string) (int, error) {
+	value := req.Params.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badRequest("invalid %s specified", name)
+	}
+	return n, nil
+}
+
+func jsonParam(req *Request, name string, v any) error {
+	value := req.Params.Get(name)
+	if value == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return badRequest("can't parse %s JSON object", name)
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+// Package tgbotapitest provides a fake Telegram Bot API server for tests.
+//
+// The server keeps chats, messages, updates and files in memory and
+// implements the core methods of the Bot API, so a BotAPI can be tested end
+// to end without network access:
+//
+//	server := tgbotapitest.NewServer("123:token")
+//	defer server.Close()
+//
+//	bot, err := server.NewBot()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	bot.Send(tgbotapi.NewMessage(1, "hello"))
+//
+//	messages := server.Messages(1)
+//
+// Methods it doesn't implement fail with a 404 "Not Found" error, as they
+// do in the Bot API.
+//
+// Recorder records the requests of a bot and their responses to a cassette
+// file, and Replayer serves them back in regression tests.
+package tgbotapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/OvyFlash/telegram-bot-api"
+)
+
+// Request is a Bot API request received by the server.
+type Request struct {
+	// Method is the Bot API method name, such as "sendMessage".
+	Method string
+	// Params are the request parameters, including the file IDs and URLs of
+	// files that weren't uploaded.
+	Params url.Values
+	// Files are the files uploaded with the request.
+	Files []UploadedFile
+}
+
+// UploadedFile is a file uploaded in a multipart request.
+type UploadedFile struct {
+	// Field is the request field of the file, such as "photo".
+	Field string
+	// FileName is the name the file was uploaded with.
+	FileName string
+	// Data is the content of the file.
+	Data []byte
+}
+
+// Server is a fake Telegram Bot API server.
+type Server struct {
+	// Bot is the user returned by getMe. It must not be changed after the
+	// first request.
+	Bot tgbotapi.User
+
+	token  string
+	server *httptest.Server
+
+	mu           sync.Mutex
+	chats        map[int64]*chatState
+	updates      []tgbotapi.Update
+	nextUpdateID int
+	updatesAdded chan struct{}
+	files        map[string]*storedFile
+	nextFileID   int
+	webhook      tgbotapi.WebhookInfo
+	requests     []Request
+}
+
+type chatState struct {
+	chat          tgbotapi.Chat
+	messages      map[int]*tgbotapi.Message
+	nextMessageID int
+}
+
+type storedFile struct {
+	file tgbotapi.File
+	name string
+	data []byte
+}
+
+// apiError is an error response of the Bot API.
+type apiError struct {
+	code        int
+	description string
+}
+
+func (e *apiError) Error() string {
+	return e.description
+}
+
+func badRequest(format string, args ...any) *apiError {
+	return &apiError{code: http.StatusBadRequest, description: "Bad Request: " + fmt.Sprintf(format, args...)}
+}
+
+// NewServer starts a server accepting requests made with token. The caller
+// should call Close when finished.
+func NewServer(token string) *Server {
+	s := &Server{
+		Bot: tgbotapi.User{
+			ID:        1,
+			IsBot:     true,
+			FirstName: "Test Bot",
+			UserName:  "test_bot",
+		},
+		token:        token,
+		chats:        make(map[int64]*chatState),
+		nextUpdateID: 1,
+		updatesAdded: make(chan struct{}),
+		files:        make(map[string]*storedFile),
+	}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// APIEndpoint returns the API endpoint to configure with
+// tgbotapi.WithAPIEndpoint.
+func (s *Server) APIEndpoint() string {
+	return s.server.URL + "/bot%s/%s"
+}
+
+// FileEndpoint returns the file endpoint to configure with
+// tgbotapi.WithFileEndpoint.
+func (s *Server) FileEndpoint() string {
+	return s.server.URL + "/file/bot%s/%s"
+}
+
+// NewBot creates a BotAPI using the server. Options are applied after the
+// endpoints and the HTTP client of the server.
+func (s *Server) NewBot(options ...tgbotapi.BotAPIOption) (*tgbotapi.BotAPI, error) {
+	options = append([]tgbotapi.BotAPIOption{
+		tgbotapi.WithAPIEndpoint(s.APIEndpoint()),
+		tgbotapi.WithFileEndpoint(s.FileEndpoint()),
+		tgbotapi.WithHTTPClient(s.server.Client()),
+	}, options...)
+
+	return tgbotapi.NewBotAPIWithOptions(s.token, options...)
+}
+
+// AddUpdate queues an update for getUpdates. A zero UpdateID is replaced
+// with the next ID. It returns the queued update.
+func (s *Server) AddUpdate(update tgbotapi.Update) tgbotapi.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addUpdate(update)
+}
+
+// AddMessage stores a message sent to the bot and queues an update for it.
+// The message gets the next message ID of its chat, and the current time
+// if Date is zero.
+func (s *Server) AddMessage(message tgbotapi.Message) tgbotapi.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.storeMessage(message.Chat.ID, message)
+	return s.addUpdate(tgbotapi.Update{Message: &stored})
+}
+
+// AddFile stores a file that can be sent by its ID or downloaded, as if it
+// had been uploaded before. It returns the file ID.
+func (s *Server) AddFile(name string, data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.storeFile("documents", name, data).file.FileID
+}
+
+// FileContent returns the content of the file with the given ID.
+func (s *Server) FileContent(fileID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[fileID]
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(file.data), true
+}
+
+// Messages returns the messages of a chat ordered by ID.
+func (s *Server) Messages(chatID int64) []tgbotapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.chats[chatID]
+	if !ok {
+		return nil
+	}
+
+	messages := make([]tgbotapi.Message, 0, len(state.messages))
+	for id := 1; id < state.nextMessageID; id++ {
+		if message, ok := state.messages[id]; ok {
+			messages = append(messages, *message)
+		}
+	}
+	return messages
+}
+
+// Requests returns the Bot API requests received by the server.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// ServeHTTP serves Bot API requests and file downloads.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/file/bot"); ok {
+		token, filePath, _ := strings.Cut(rest, "/")
+		if token != s.token {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.serveFile(w, r, filePath)
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, "/bot")
+	if !ok {
+		writeError(w, &apiError{code: http.StatusNotFound, description: "Not Found"})
+		return
+	}
+	token, method, _ := strings.Cut(rest, "/")
+	if token != s.token {
+		writeError(w, &apiError{code: http.StatusUnauthorized, description: "Unauthorized"})
+		return
+	}
+
+	req, err := parseRequest(r, method)
+	if err != nil {
+		writeError(w, badRequest("%s", err))
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, *req)
+	s.mu.Unlock()
+
+	handler, ok := methods[strings.ToLower(method)]
+	if !ok {
+		writeError(w, &apiError{code: http.StatusNotFound, description: "Not Found"})
+		return
+	}
+
+	result, err := handler(s, r, req)
+	if err != nil {
+		apiErr, ok := err.(*apiError)
+		if !ok {
+			apiErr = &apiError{code: http.StatusInternalServerError, description: "Internal Server Error: " + err.Error()}
+		}
+		writeError(w, apiErr)
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, &apiError{code: http.StatusInternalServerError, description: "Internal Server Error: " + err.Error()})
+		return
+	}
+	writeResponse(w, http.StatusOK, tgbotapi.APIResponse{Ok: true, Result: data})
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, filePath string) {
+	s.mu.Lock()
+	var found *storedFile
+	for _, file := range s.files {
+		if file.file.FilePath == filePath {
+			found = file
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if found == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, path.Base(filePath), time.Time{}, bytes.NewReader(found.data))
+}
+
+// parseRequest reads the parameters and uploaded files of a request.
+func parseRequest(r *http.Request, method string) (*Request, error) {
+	req := &Request{Method: method}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+		for field, headers := range r.MultipartForm.File {
+			for _, header := range headers {
+				file, err := header.Open()
+				if err != nil {
+					return nil, err
+				}
+				data, err := io.ReadAll(file)
+				file.Close()
+				if err != nil {
+					return nil, err
+				}
+				req.Files = append(req.Files, UploadedFile{Field: field, FileName: header.Filename, Data: data})
+			}
+		}
+		slices.SortFunc(req.Files, func(a, b UploadedFile) int {
+			return strings.Compare(a.Field, b.Field)
+		})
+	} else if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	req.Params = r.Form
+	return req, nil
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeResponse(w, err.code, tgbotapi.APIResponse{ErrorCode: err.code, Description: err.description})
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp tgbotapi.APIResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,263 @@
+package tgbotapitest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/OvyFlash/telegram-bot-api"
+)
+
+func newTestBot(t *testing.T) (*Server, *tgbotapi.BotAPI) {
+	t.Helper()
+
+	server := NewServer("123:token")
+	t.Cleanup(server.Close)
+
+	bot, err := server.NewBot(tgbotapi.WithLoggingDisabled())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, bot
+}
+
+func TestGetMe(t *testing.T) {
+	server, bot := newTestBot(t)
+	if bot.Self != server.Bot {
+		t.Fatalf("unexpected bot user %+v", bot.Self)
+	}
+
+	_, err := tgbotapi.NewBotAPIWithOptions("456:other",
+		tgbotapi.WithAPIEndpoint(server.APIEndpoint()),
+		tgbotapi.WithLoggingDisabled(),
+	)
+	if !errors.Is(err, tgbotapi.ErrUnauthorized) {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+
+	if _, err := bot.MakeRequest("unknownMethod", nil); err == nil || err.Error() != "Not Found" {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	server, bot := newTestBot(t)
+
+	config := tgbotapi.NewMessage(10, "<b>hello</b> world")
+	config.ParseMode = tgbotapi.ModeHTML
+	sent, err := bot.Send(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.MessageID != 1 || sent.Chat.ID != 10 || sent.Text != "hello world" || len(sent.Entities) != 1 || sent.Entities[0].Type != "bold" {
+		t.Fatalf("unexpected message %+v", sent)
+	}
+
+	config.Text = "<b>unclosed"
+	if _, err := bot.Send(config); !errors.Is(err, tgbotapi.ErrCantParseEntities) {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+
+	reply := tgbotapi.NewMessage(10, "reply")
+	reply.ReplyParameters.MessageID = sent.MessageID
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("a", "b")))
+	replied, err := bot.Send(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replied.ReplyToMessage == nil || replied.ReplyToMessage.MessageID != sent.MessageID || replied.ReplyMarkup == nil {
+		t.Fatalf("unexpected reply %+v", replied)
+	}
+
+	edited, err := bot.Send(tgbotapi.NewEditMessageText(10, sent.MessageID, "edited"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Text != "edited" || edited.Entities != nil || edited.EditDate == 0 {
+		t.Fatalf("unexpected edited message %+v", edited)
+	}
+	if _, err := bot.Send(tgbotapi.NewEditMessageText(10, sent.MessageID, "edited")); !errors.Is(err, tgbotapi.ErrMessageNotModified) {
+		t.Fatalf("expected a not modified error, got %v", err)
+	}
+	if _, err := bot.Send(tgbotapi.NewEditMessageText(10, 100, "edited")); !errors.Is(err, tgbotapi.ErrMessageToEditNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	messages := server.Messages(10)
+	if len(messages) != 2 || messages[0].Text != "edited" || messages[1].Text != "reply" {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	server, bot := newTestBot(t)
+
+	photo := tgbotapi.NewPhoto(10, tgbotapi.FileBytes{Name: "photo.jpg", Bytes: []byte("image")})
+	photo.Caption = "caption"
+	sent, err := bot.Send(photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent.Photo) != 1 || sent.Caption != "caption" {
+		t.Fatalf("unexpected message %+v", sent)
+	}
+	fileID := sent.Photo[0].FileID
+
+	var content bytes.Buffer
+	file, err := bot.DownloadFile(fileID, &content, tgbotapi.DownloadConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.String() != "image" || file.FileSize != 5 {
+		t.Fatalf("unexpected file %+v with content %q", file, content.String())
+	}
+
+	resent, err := bot.Send(tgbotapi.NewDocument(10, tgbotapi.FileID(server.AddFile("doc.txt", []byte("text")))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := server.FileContent(resent.Document.FileID); !ok || string(data) != "text" {
+		t.Fatalf("unexpected document content %q", data)
+	}
+
+	if _, err := bot.Send(tgbotapi.NewPhoto(10, tgbotapi.FileID("unknown"))); !errors.Is(err, tgbotapi.ErrInvalidFileID) {
+		t.Fatalf("expected an invalid file ID error, got %v", err)
+	}
+
+	requests := server.Requests()
+	last := requests[len(requests)-1]
+	if last.Method != "sendPhoto" || last.Params.Get("photo") != "unknown" {
+		t.Fatalf("unexpected last request %+v", last)
+	}
+	for _, request := range requests {
+		if request.Method == "sendPhoto" && len(request.Files) == 1 {
+			if request.Files[0].Field != "photo" || string(request.Files[0].Data) != "image" {
+				t.Fatalf("unexpected uploaded file %+v", request.Files[0])
+			}
+		}
+	}
+}
+
+func TestMediaGroup(t *testing.T) {
+	server, bot := newTestBot(t)
+
+	photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileBytes{Name: "photo.jpg", Bytes: []byte("image")})
+	photo.Caption = "<b>album</b>"
+	photo.ParseMode = tgbotapi.ModeHTML
+	document := tgbotapi.NewInputMediaDocument(tgbotapi.FileID(server.AddFile("doc.txt", []byte("text"))))
+
+	messages, err := bot.SendMediaGroup(tgbotapi.NewMediaGroup(10, []tgbotapi.InputMedia{&photo, &document}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || len(messages[0].Photo) != 1 || messages[1].Document == nil {
+		t.Fatalf("unexpected messages %+v", messages)
+	}
+	if messages[0].Caption != "album" || len(messages[0].CaptionEntities) != 1 {
+		t.Fatalf("unexpected caption %q %+v", messages[0].Caption, messages[0].CaptionEntities)
+	}
+	if messages[0].MediaGroupID == "" || messages[0].MediaGroupID != messages[1].MediaGroupID {
+		t.Fatalf("unexpected media group IDs %q and %q", messages[0].MediaGroupID, messages[1].MediaGroupID)
+	}
+	if data, ok := server.FileContent(messages[0].Photo[0].FileID); !ok || string(data) != "image" {
+		t.Fatalf("unexpected photo content %q", data)
+	}
+	if len(server.Messages(10)) != 2 {
+		t.Fatalf("unexpected stored messages %+v", server.Messages(10))
+	}
+
+	if _, err := bot.SendMediaGroup(tgbotapi.NewMediaGroup(10, []tgbotapi.InputMedia{&photo})); err == nil {
+		t.Fatal("expected an error for a media group with one item")
+	}
+}
+
+func TestEditMessageMedia(t *testing.T) {
+	server, bot := newTestBot(t)
+
+	sent, err := bot.Send(tgbotapi.NewPhoto(10, tgbotapi.FileBytes{Name: "photo.jpg", Bytes: []byte("image")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := tgbotapi.NewInputMediaDocument(tgbotapi.FileBytes{Name: "doc.txt", Bytes: []byte("text")})
+	document.Caption = "new"
+	edited, err := bot.Send(tgbotapi.NewEditMessageMedia(10, sent.MessageID, &document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Photo != nil || edited.Document == nil || edited.Caption != "new" || edited.EditDate == 0 {
+		t.Fatalf("unexpected edited message %+v", edited)
+	}
+	if data, ok := server.FileContent(edited.Document.FileID); !ok || string(data) != "text" {
+		t.Fatalf("unexpected document content %q", data)
+	}
+
+	text, err := bot.Send(tgbotapi.NewMessage(10, "text"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Send(tgbotapi.NewEditMessageMedia(10, text.MessageID, &document)); err == nil {
+		t.Fatal("expected an error editing the media of a text message")
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	server, bot := newTestBot(t)
+
+	update := server.AddMessage(tgbotapi.Message{
+		Chat: tgbotapi.Chat{ID: 10},
+		From: &tgbotapi.User{ID: 10},
+		Text: "/start",
+	})
+	updates, err := bot.GetUpdates(tgbotapi.NewUpdate(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].UpdateID != 1 || updates[0].Message.Text != "/start" || update.Message.MessageID != 1 {
+		t.Fatalf("unexpected updates %+v", updates)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.AddUpdate(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "query"}})
+	}()
+	config := tgbotapi.NewUpdate(2)
+	config.Timeout = 5
+	updates, err = bot.GetUpdates(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0].UpdateID != 2 || updates[0].CallbackQuery == nil {
+		t.Fatalf("unexpected updates %+v", updates)
+	}
+
+	webhook, err := tgbotapi.NewWebhook("https://example.com/hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Request(webhook); err != nil {
+		t.Fatal(err)
+	}
+	info, err := bot.GetWebhookInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.URL != "https://example.com/hook" || info.PendingUpdateCount != 1 {
+		t.Fatalf("unexpected webhook info %+v", info)
+	}
+
+	var apiErr *tgbotapi.Error
+	if _, err := bot.GetUpdates(tgbotapi.NewUpdate(0)); !errors.As(err, &apiErr) || apiErr.Code != http.StatusConflict {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+
+	if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{DropPendingUpdates: true}); err != nil {
+		t.Fatal(err)
+	}
+	updates, err = bot.GetUpdates(tgbotapi.NewUpdate(0))
+	if err != nil || len(updates) != 0 {
+		t.Fatalf("expected no updates, got %+v, %v", updates, err)
+	}
+}
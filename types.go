@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // APIResponse is a response from the Telegram API with the result
@@ -232,6 +233,67 @@ func (u *Update) CallbackData() string {
 	return ""
 }
 
+// Type returns the kind of the update as one of the UpdateType constants.
+// It returns an empty string if the update kind is unknown.
+func (u *Update) Type() string {
+	switch {
+	case u.Message != nil:
+		return UpdateTypeMessage
+	case u.EditedMessage != nil:
+		return UpdateTypeEditedMessage
+	case u.ChannelPost != nil:
+		return UpdateTypeChannelPost
+	case u.EditedChannelPost != nil:
+		return UpdateTypeEditedChannelPost
+	case u.BusinessConnection != nil:
+		return UpdateTypeBusinessConnection
+	case u.BusinessMessage != nil:
+		return UpdateTypeBusinessMessage
+	case u.EditedBusinessMessage != nil:
+		return UpdateTypeEditedBusinessMessage
+	case u.DeletedBusinessMessages != nil:
+		return UpdateTypeDeletedBusinessMessages
+	case u.GuestMessage != nil:
+		return UpdateTypeGuestMessage
+	case u.MessageReaction != nil:
+		return UpdateTypeMessageReaction
+	case u.MessageReactionCount != nil:
+		return UpdateTypeMessageReactionCount
+	case u.InlineQuery != nil:
+		return UpdateTypeInlineQuery
+	case u.ChosenInlineResult != nil:
+		return UpdateTypeChosenInlineResult
+	case u.CallbackQuery != nil:
+		return UpdateTypeCallbackQuery
+	case u.ShippingQuery != nil:
+		return UpdateTypeShippingQuery
+	case u.PreCheckoutQuery != nil:
+		return UpdateTypePreCheckoutQuery
+	case u.PurchasedPaidMedia != nil:
+		return UpdateTypePurchasedPaidMedia
+	case u.Poll != nil:
+		return UpdateTypePoll
+	case u.PollAnswer != nil:
+		return UpdateTypePollAnswer
+	case u.MyChatMember != nil:
+		return UpdateTypeMyChatMember
+	case u.ChatMember != nil:
+		return UpdateTypeChatMember
+	case u.ChatJoinRequest != nil:
+		return UpdateTypeChatJoinRequest
+	case u.ChatBoost != nil:
+		return UpdateTypeChatBoost
+	case u.ChatBoostRemoved != nil:
+		return UpdateTypeRemovedChatBoost
+	case u.ManagedBot != nil:
+		return UpdateTypeManagedBot
+	case u.Subscription != nil:
+		return UpdateTypeSubscription
+	default:
+		return ""
+	}
+}
+
 // FromChat returns the chat where an update occurred.
 func (u *Update) FromChat() *Chat {
 	switch {
@@ -1242,8 +1304,11 @@ func (m *Message) CommandWithAt() string {
 	}
 
 	// IsCommand() checks that the message begins with a bot_command entity
-	entity := m.Entities[0]
-	return m.Text[1:entity.Length]
+	end, ok := UTF16Index(m.Text, m.Entities[0].Length)
+	if !ok || end == 0 {
+		return ""
+	}
+	return m.Text[1:end]
 }
 
 // CommandArguments checks if the message was a command and if it was,
@@ -1261,13 +1326,13 @@ func (m *Message) CommandArguments() string {
 	}
 
 	// IsCommand() checks that the message begins with a bot_command entity
-	entity := m.Entities[0]
-
-	if len(m.Text) == entity.Length {
+	end, ok := UTF16Index(m.Text, m.Entities[0].Length)
+	if !ok || end == len(m.Text) {
 		return "" // The command makes up the whole message
 	}
 
-	return m.Text[entity.Length+1:]
+	_, size := utf8.DecodeRuneInString(m.Text[end:])
+	return m.Text[end+size:]
 }
 
 // MessageID represents a unique message identifier.
@@ -569,6 +569,18 @@ func TestMessageCommandArgumentsWithMalformedArguments(t *testing.T) {
 	}
 }
 
+func TestMessageCommandWithMultiByteText(t *testing.T) {
+	message := Message{Text: "/команда—привет 👋 мир"}
+	message.Entities = []MessageEntity{{Type: "bot_command", Offset: 0, Length: 8}}
+
+	if message.CommandWithAt() != "команда" {
+		t.Fatalf("unexpected command %q", message.CommandWithAt())
+	}
+	if message.CommandArguments() != "привет 👋 мир" {
+		t.Fatalf("unexpected arguments %q", message.CommandArguments())
+	}
+}
+
 func TestMessageCommandArgumentsWithoutArguments(t *testing.T) {
 	message := Message{Text: "/command"}
 	if message.CommandArguments() != "" {
@@ -863,3 +875,23 @@ var (
 	_ RequestFileData = (*FileID)(nil)
 	_ RequestFileData = (*fileAttach)(nil)
 )
+
+func TestUpdateType(t *testing.T) {
+	tests := []struct {
+		update Update
+		want   string
+	}{
+		{Update{Message: &Message{}}, UpdateTypeMessage},
+		{Update{EditedChannelPost: &Message{}}, UpdateTypeEditedChannelPost},
+		{Update{CallbackQuery: &CallbackQuery{}}, UpdateTypeCallbackQuery},
+		{Update{ChatBoostRemoved: &ChatBoostRemoved{}}, UpdateTypeRemovedChatBoost},
+		{Update{Subscription: &BotSubscriptionUpdated{}}, UpdateTypeSubscription},
+		{Update{}, ""},
+	}
+
+	for _, test := range tests {
+		if got := test.update.Type(); got != test.want {
+			t.Fatalf("expected update type %q, got %q", test.want, got)
+		}
+	}
+}
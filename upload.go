@@ -111,6 +111,16 @@ func requestFile(name string, data RequestFileData) RequestFile {
 	}
 }
 
+// buildRequestPayload builds a form payload for plain requests and a multipart
+// payload when files are present. A nil files slice means a plain request.
+// A non-nil progress is called as the files are written.
+func buildRequestPayload(params Params, files []RequestFile, progress UploadProgressFunc) (requestPayload, error) {
+	if files == nil {
+		return buildFormPayload(params), nil
+	}
+	return buildMultipartPayload(params, files, progress)
+}
+
 func buildFormPayload(params Params) requestPayload {
 	values := buildParams(params)
 	return requestPayload{
@@ -119,12 +129,12 @@ func buildFormPayload(params Params) requestPayload {
 	}
 }
 
-func buildMultipartPayload(params Params, files []RequestFile) (requestPayload, error) {
+func buildMultipartPayload(params Params, files []RequestFile, progress UploadProgressFunc) (requestPayload, error) {
 	reader, writer := io.Pipe()
 	multipartWriter := multipart.NewWriter(writer)
 
 	go func() {
-		if err := writeMultipartPayload(multipartWriter, params, files); err != nil {
+		if err := writeMultipartPayload(multipartWriter, params, files, progress); err != nil {
 			_ = writer.CloseWithError(err)
 			return
 		}
@@ -142,7 +152,7 @@ func buildMultipartPayload(params Params, files []RequestFile) (requestPayload,
 	}, nil
 }
 
-func writeMultipartPayload(writer *multipart.Writer, params Params, files []RequestFile) error {
+func writeMultipartPayload(writer *multipart.Writer, params Params, files []RequestFile, progress UploadProgressFunc) error {
 	for field, value := range params {
 		if err := writer.WriteField(field, value); err != nil {
 			return fmt.Errorf("write multipart field %q: %w", field, err)
@@ -155,7 +165,7 @@ func writeMultipartPayload(writer *multipart.Writer, params Params, files []Requ
 		}
 
 		if file.Data.NeedsUpload() {
-			if err := writeMultipartUpload(writer, file); err != nil {
+			if err := writeMultipartUpload(writer, file, progress); err != nil {
 				return err
 			}
 			continue
@@ -169,7 +179,7 @@ func writeMultipartPayload(writer *multipart.Writer, params Params, files []Requ
 	return nil
 }
 
-func writeMultipartUpload(writer *multipart.Writer, file RequestFile) error {
+func writeMultipartUpload(writer *multipart.Writer, file RequestFile, progress UploadProgressFunc) error {
 	name, reader, err := file.Data.UploadData()
 	if err != nil {
 		return fmt.Errorf("open upload %q: %w", file.Name, err)
@@ -186,6 +196,12 @@ func writeMultipartUpload(writer *multipart.Writer, file RequestFile) error {
 		return fmt.Errorf("create multipart file %q: %w", file.Name, err)
 	}
 
+	var reporter *progressWriter
+	if progress != nil {
+		reporter = newProgressWriter(part, file.Name, name, file.Data, progress)
+		part = reporter
+	}
+
 	_, copyErr := io.Copy(part, reader)
 	closeErr := closeUploadReader(reader)
 	if copyErr != nil {
@@ -197,6 +213,9 @@ func writeMultipartUpload(writer *multipart.Writer, file RequestFile) error {
 	if closeErr != nil {
 		return fmt.Errorf("close upload %q: %w", file.Name, closeErr)
 	}
+	if reporter != nil {
+		reporter.done()
+	}
 
 	return nil
 }
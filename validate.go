@@ -0,0 +1,255 @@
+package tgbotapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Bot API limits checked by Validate.
+const (
+	maxCallbackDataLength = 64
+	minMediaGroupSize     = 2
+	maxMediaGroupSize     = 10
+	maxPollQuestionLength = 300
+	minPollOptions        = 2
+	maxPollOptions        = 12
+	maxPollOptionLength   = 100
+)
+
+// ValidationError describes a config field that violates a documented Bot
+// API limit. Field is the name of the request parameter, such as "text" or
+// "reply_markup.inline_keyboard[0][1].callback_data".
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// Validator is implemented by configs that can be checked before they are
+// sent. Validate returns a *ValidationError for the first invalid field.
+type Validator interface {
+	Validate() error
+}
+
+// WithValidation configures the bot to validate configs implementing
+// Validator in Request and Send, returning the validation error instead of
+// sending an invalid request.
+func WithValidation(enabled bool) BotAPIOption {
+	return func(config *botAPIConfig) error {
+		config.validate = enabled
+		return nil
+	}
+}
+
+// Validate checks the text and reply markup of the message.
+func (config MessageConfig) Validate() error {
+	if err := validateText("text", config.Text, config.ParseMode, 1, MaxMessageTextLength); err != nil {
+		return err
+	}
+	return validateReplyMarkup(config.ReplyMarkup)
+}
+
+// Validate checks the text and reply markup of the message.
+func (config EditMessageTextConfig) Validate() error {
+	if err := validateText("text", config.Text, config.ParseMode, 1, MaxMessageTextLength); err != nil {
+		return err
+	}
+	return validateReplyMarkup(config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config EditMessageCaptionConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config CopyMessageConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config PhotoConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config SendLivePhotoConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config AudioConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config DocumentConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config VideoConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config AnimationConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config VoiceConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the caption and reply markup of the message.
+func (config PaidMediaConfig) Validate() error {
+	return validateCaption(config.Caption, config.ParseMode, config.ReplyMarkup)
+}
+
+// Validate checks the number and types of media in the group and their
+// captions. Documents and audio can only be grouped with media of the same
+// type, while photos and videos can be mixed.
+func (config MediaGroupConfig) Validate() error {
+	if len(config.Media) < minMediaGroupSize || len(config.Media) > maxMediaGroupSize {
+		return &ValidationError{
+			Field:   "media",
+			Message: fmt.Sprintf("must contain %d-%d items, got %d", minMediaGroupSize, maxMediaGroupSize, len(config.Media)),
+		}
+	}
+
+	var group string
+	for i, media := range config.Media {
+		field := fmt.Sprintf("media[%d]", i)
+		if media == nil {
+			return &ValidationError{Field: field, Message: "must not be nil"}
+		}
+
+		mediaType := media.getType()
+		mediaGroup := mediaType
+		switch mediaType {
+		case "photo", "video", "live_photo":
+			mediaGroup = "photo and video"
+		case "audio", "document":
+		default:
+			return &ValidationError{Field: field + ".type", Message: fmt.Sprintf("%q can't be sent in a media group", mediaType)}
+		}
+		if group == "" {
+			group = mediaGroup
+		} else if mediaGroup != group {
+			return &ValidationError{Field: field + ".type", Message: fmt.Sprintf("%q can't be grouped with %s", mediaType, group)}
+		}
+
+		if captioned, ok := media.(interface{ caption() (string, string) }); ok {
+			caption, parseMode := captioned.caption()
+			if err := validateText(field+".caption", caption, parseMode, 0, MaxCaptionLength); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// caption returns the caption of the media and its parse mode.
+func (media *BaseInputMedia) caption() (string, string) {
+	return media.Caption, media.ParseMode
+}
+
+// Validate checks the question and options of the poll and the reply markup.
+func (config SendPollConfig) Validate() error {
+	if err := validateText("question", config.Question, config.QuestionParseMode, 1, maxPollQuestionLength); err != nil {
+		return err
+	}
+
+	if len(config.Options) < minPollOptions || len(config.Options) > maxPollOptions {
+		return &ValidationError{
+			Field:   "options",
+			Message: fmt.Sprintf("must contain %d-%d options, got %d", minPollOptions, maxPollOptions, len(config.Options)),
+		}
+	}
+	for i, option := range config.Options {
+		field := fmt.Sprintf("options[%d].text", i)
+		if err := validateText(field, option.Text, option.TextParseMode, 1, maxPollOptionLength); err != nil {
+			return err
+		}
+	}
+
+	return validateReplyMarkup(config.ReplyMarkup)
+}
+
+func validateCaption(caption, parseMode string, replyMarkup any) error {
+	if err := validateText("caption", caption, parseMode, 0, MaxCaptionLength); err != nil {
+		return err
+	}
+	return validateReplyMarkup(replyMarkup)
+}
+
+// validateText checks the length of text after entities parsing, in UTF-16
+// code units. Text in legacy Markdown is checked as is.
+func validateText(field, text, parseMode string, minLength, maxLength int) error {
+	if parseMode != "" {
+		parsed, _, err := ParseMarkup(text, parseMode)
+		var parseErr *EntityParseError
+		if errors.As(err, &parseErr) {
+			return &ValidationError{Field: field, Message: err.Error()}
+		}
+		if err == nil {
+			text = parsed
+		}
+	}
+
+	if minLength > 0 && strings.TrimSpace(text) == "" {
+		return &ValidationError{Field: field, Message: "must not be empty"}
+	}
+	if length := UTF16Len(text); length < minLength || length > maxLength {
+		return &ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("must be %d-%d characters long, got %d", minLength, maxLength, length),
+		}
+	}
+
+	return nil
+}
+
+// validateReplyMarkup checks the buttons of an inline keyboard. Other reply
+// markup is not checked.
+func validateReplyMarkup(replyMarkup any) error {
+	var keyboard *InlineKeyboardMarkup
+	switch markup := replyMarkup.(type) {
+	case InlineKeyboardMarkup:
+		keyboard = &markup
+	case *InlineKeyboardMarkup:
+		keyboard = markup
+	}
+	if keyboard == nil {
+		return nil
+	}
+
+	for i, row := range keyboard.InlineKeyboard {
+		for j, button := range row {
+			field := fmt.Sprintf("reply_markup.inline_keyboard[%d][%d]", i, j)
+			if button.Text == "" {
+				return &ValidationError{Field: field + ".text", Message: "must not be empty"}
+			}
+			if data := button.CallbackData; data != nil && (len(*data) < 1 || len(*data) > maxCallbackDataLength) {
+				return &ValidationError{
+					Field:   field + ".callback_data",
+					Message: fmt.Sprintf("must be 1-%d bytes long, got %d", maxCallbackDataLength, len(*data)),
+				}
+			}
+			if button.URL != nil {
+				if u, err := url.Parse(*button.URL); err != nil || u.Scheme == "" {
+					return &ValidationError{Field: field + ".url", Message: fmt.Sprintf("%q must be an absolute URL with a scheme", *button.URL)}
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package tgbotapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	photo := NewInputMediaPhoto(FileID("photo"))
+	audio := NewInputMediaAudio(FileID("audio"))
+	video := NewInputMediaVideo(FileID("video"))
+	longCaption := NewInputMediaPhoto(FileID("photo"))
+	longCaption.Caption = strings.Repeat("a", MaxCaptionLength+1)
+
+	tooMany := make([]InputMedia, 11)
+	for i := range tooMany {
+		tooMany[i] = &photo
+	}
+
+	withKeyboard := func(buttons ...InlineKeyboardButton) MessageConfig {
+		msg := NewMessage(1, "text")
+		msg.ReplyMarkup = NewInlineKeyboardMarkup(NewInlineKeyboardRow(buttons...))
+		return msg
+	}
+	htmlMessage := NewMessage(1, "<b>text")
+	htmlMessage.ParseMode = ModeHTML
+
+	tests := []struct {
+		name   string
+		config Validator
+		field  string
+	}{
+		{"message", NewMessage(1, "text"), ""},
+		{"empty text", NewMessage(1, " \n"), "text"},
+		{"long text", NewMessage(1, strings.Repeat("a", MaxMessageTextLength+1)), "text"},
+		{"invalid HTML", htmlMessage, "text"},
+		{"edit text", NewEditMessageText(1, 2, "text"), ""},
+		{"empty edit text", NewEditMessageText(1, 2, ""), "text"},
+		{"media group", NewMediaGroup(1, []InputMedia{&photo, &video}), ""},
+		{"one media", NewMediaGroup(1, []InputMedia{&photo}), "media"},
+		{"too many media", NewMediaGroup(1, tooMany), "media"},
+		{"audio with photo", NewMediaGroup(1, []InputMedia{&photo, &audio}), "media[1].type"},
+		{"long media caption", NewMediaGroup(1, []InputMedia{&photo, &longCaption}), "media[1].caption"},
+		{"poll", NewPoll(1, "?", NewPollOption("a"), NewPollOption("b")), ""},
+		{"poll with one option", NewPoll(1, "?", NewPollOption("a")), "options"},
+		{"empty poll option", NewPoll(1, "?", NewPollOption("a"), NewPollOption("")), "options[1].text"},
+		{"callback data", withKeyboard(NewInlineKeyboardButtonData("a", strings.Repeat("x", 64))), ""},
+		{"long callback data", withKeyboard(NewInlineKeyboardButtonData("a", strings.Repeat("x", 65))),
+			"reply_markup.inline_keyboard[0][0].callback_data"},
+		{"URL without scheme", withKeyboard(NewInlineKeyboardButtonData("a", "b"), NewInlineKeyboardButtonURL("c", "example.com")),
+			"reply_markup.inline_keyboard[0][1].url"},
+		{"long caption", PhotoConfig{Caption: strings.Repeat("😀", 513)}, "caption"},
+	}
+
+	for _, test := range tests {
+		err := test.config.Validate()
+		if test.field == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a validation error, got %v", test.name, err)
+			continue
+		}
+		if validationErr.Field != test.field {
+			t.Errorf("%s: unexpected field %q in %v", test.name, validationErr.Field, err)
+		}
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	requests := 0
+	client := fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			requests++
+			if strings.HasSuffix(req.URL.Path, "/getMe") {
+				return okGetMeResponse(), nil
+			}
+			return resultResponse(`{"message_id":1}`), nil
+		},
+	}
+
+	bot, err := NewBotAPIWithOptions("token", WithHTTPClient(client), WithValidation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = bot.Send(NewMessage(1, ""))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "text" {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("invalid message was sent")
+	}
+
+	if _, err := bot.Send(NewMessage(1, "text")); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Fatalf("valid message wasn't sent")
+	}
+}
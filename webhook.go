@@ -0,0 +1,206 @@
+package tgbotapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// WebhookSecretTokenHeader is the header containing the secret token set with
+// WebhookConfig.SecretToken.
+const WebhookSecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// DefaultWebhookMaxBodySize is the default limit of a webhook request body.
+const DefaultWebhookMaxBodySize = 1 << 20
+
+// TelegramWebhookNetworks returns the IP ranges Telegram sends webhook
+// requests from.
+//
+// See https://core.telegram.org/bots/webhooks#the-short-version for details.
+func TelegramWebhookNetworks() []netip.Prefix {
+	return []netip.Prefix{
+		netip.MustParsePrefix("149.154.160.0/20"),
+		netip.MustParsePrefix("91.108.4.0/22"),
+	}
+}
+
+// WebhookHandler is an http.Handler receiving updates via webhook.
+//
+// Unlike [BotAPI.ListenForWebhook], it is not registered on
+// http.DefaultServeMux and verifies requests before handling them.
+type WebhookHandler struct {
+	// SecretToken must match the X-Telegram-Bot-Api-Secret-Token header of
+	// every request. It should be the same as WebhookConfig.SecretToken.
+	// An empty token disables the check.
+	SecretToken string
+	// MaxBodySize limits the size of a request body.
+	// Defaults to DefaultWebhookMaxBodySize.
+	MaxBodySize int64
+	// AllowedNetworks restricts the source addresses of requests.
+	// An empty list allows all addresses. The address is taken from the
+	// connection, so this does not work behind a reverse proxy.
+	AllowedNetworks []netip.Prefix
+	// Handler handles received updates. A returned error is reported to
+	// Telegram with a server error so the update is delivered again.
+	Handler UpdateHandlerFunc
+	// Reply handles received updates instead of Handler when set. The
+	// returned request is written into the webhook response, saving an API
+	// call.
+	Reply WebhookReplyFunc
+	// Bot sends replies that upload files, which cannot be written into a
+	// webhook response.
+	Bot *BotAPI
+}
+
+// WebhookReplyFunc handles an update and may return a request to make in
+// reply. A nil Chattable means there is nothing to reply with.
+type WebhookReplyFunc func(ctx context.Context, update Update) (Chattable, error)
+
+// NewWebhookHandler creates a WebhookHandler verifying secretToken and passing
+// updates to handler.
+func NewWebhookHandler(secretToken string, handler UpdateHandlerFunc) *WebhookHandler {
+	return &WebhookHandler{
+		SecretToken: secretToken,
+		Handler:     handler,
+	}
+}
+
+// NewWebhookReplyHandler creates a WebhookHandler verifying secretToken and
+// replying to updates with the requests returned by reply.
+//
+// Replies are written into the webhook response with [WriteToHTTPResponse].
+// Replies uploading files are sent with bot instead.
+//
+// See https://core.telegram.org/bots/api#making-requests-when-getting-updates
+// for details.
+func NewWebhookReplyHandler(bot *BotAPI, secretToken string, reply WebhookReplyFunc) *WebhookHandler {
+	return &WebhookHandler{
+		SecretToken: secretToken,
+		Reply:       reply,
+		Bot:         bot,
+	}
+}
+
+// ServeHTTP verifies and handles a webhook request.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	update, status, err := h.readUpdate(w, r)
+	if err != nil {
+		writeWebhookError(w, status, err)
+		return
+	}
+
+	if h.Reply != nil {
+		h.serveReply(r.Context(), w, *update)
+		return
+	}
+
+	if err := h.handle(r.Context(), *update); err != nil {
+		writeWebhookError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) serveReply(ctx context.Context, w http.ResponseWriter, update Update) {
+	reply, err := h.Reply(ctx, update)
+	if err != nil {
+		writeWebhookError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if reply == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	err = WriteToHTTPResponse(w, reply)
+	if errors.Is(err, errHTTPResponseUpload) {
+		err = h.sendReply(ctx, reply)
+		if err == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	if err != nil {
+		writeWebhookError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *WebhookHandler) sendReply(ctx context.Context, reply Chattable) error {
+	if h.Bot == nil {
+		return errors.New("webhook reply uploads files but the handler has no bot")
+	}
+
+	_, err := h.Bot.RequestWithContext(ctx, reply)
+	return err
+}
+
+func (h *WebhookHandler) handle(ctx context.Context, update Update) error {
+	if h.Handler == nil {
+		return nil
+	}
+	return h.Handler(ctx, update)
+}
+
+// readUpdate verifies the request and decodes its update. On failure it
+// returns the status code to respond with.
+func (h *WebhookHandler) readUpdate(w http.ResponseWriter, r *http.Request) (*Update, int, error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return nil, http.StatusMethodNotAllowed, errors.New("wrong HTTP method required POST")
+	}
+
+	if !h.allowedAddress(r.RemoteAddr) {
+		return nil, http.StatusForbidden, errors.New("source address is not allowed")
+	}
+
+	if h.SecretToken != "" {
+		token := r.Header.Get(WebhookSecretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.SecretToken)) != 1 {
+			return nil, http.StatusUnauthorized, errors.New("invalid secret token")
+		}
+	}
+
+	maxBodySize := h.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultWebhookMaxBodySize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	update, err := decodeWebhookUpdate(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, http.StatusRequestEntityTooLarge, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+
+	return update, http.StatusOK, nil
+}
+
+func (h *WebhookHandler) allowedAddress(remoteAddr string) bool {
+	if len(h.AllowedNetworks) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, network := range h.AllowedNetworks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
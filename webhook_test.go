@@ -0,0 +1,169 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandlerVerifiesRequests(t *testing.T) {
+	var handled []int
+	handler := NewWebhookHandler("s3cret", func(ctx context.Context, update Update) error {
+		if update.UpdateID == 13 {
+			return errors.New("handler failed")
+		}
+		handled = append(handled, update.UpdateID)
+		return nil
+	})
+	handler.MaxBodySize = 64
+	handler.AllowedNetworks = TelegramWebhookNetworks()
+
+	tests := []struct {
+		name       string
+		method     string
+		remoteAddr string
+		token      string
+		body       string
+		status     int
+	}{
+		{"valid", http.MethodPost, "149.154.167.1:443", "s3cret", `{"update_id":1}`, http.StatusOK},
+		{"mapped IPv4", http.MethodPost, "[::ffff:91.108.4.10]:443", "s3cret", `{"update_id":2}`, http.StatusOK},
+		{"wrong method", http.MethodGet, "149.154.167.1:443", "s3cret", ``, http.StatusMethodNotAllowed},
+		{"foreign address", http.MethodPost, "203.0.113.5:443", "s3cret", `{"update_id":3}`, http.StatusForbidden},
+		{"missing token", http.MethodPost, "149.154.167.1:443", "", `{"update_id":4}`, http.StatusUnauthorized},
+		{"wrong token", http.MethodPost, "149.154.167.1:443", "s3cret!", `{"update_id":5}`, http.StatusUnauthorized},
+		{"too large", http.MethodPost, "149.154.167.1:443", "s3cret", `{"update_id":6,"message":{"text":"` + strings.Repeat("a", 64) + `"}}`, http.StatusRequestEntityTooLarge},
+		{"malformed", http.MethodPost, "149.154.167.1:443", "s3cret", `{`, http.StatusBadRequest},
+		{"handler error", http.MethodPost, "149.154.167.1:443", "s3cret", `{"update_id":13}`, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, "/webhook", strings.NewReader(test.body))
+			request.RemoteAddr = test.remoteAddr
+			if test.token != "" {
+				request.Header.Set(WebhookSecretTokenHeader, test.token)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("expected status %d, got %d (%s)", test.status, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+
+	if len(handled) != 2 || handled[0] != 1 || handled[1] != 2 {
+		t.Fatalf("unexpected handled updates: %v", handled)
+	}
+}
+
+func TestWebhookHandlerWithoutRestrictions(t *testing.T) {
+	dispatcher := NewDispatcher()
+	var got int
+	dispatcher.HandleFallback(func(ctx context.Context, update Update) error {
+		got = update.UpdateID
+		return nil
+	})
+
+	handler := NewWebhookHandler("", dispatcher.Dispatch)
+	handler.AllowedNetworks = nil
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":7}`))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || got != 7 {
+		t.Fatalf("unexpected result: status %d, update %d", recorder.Code, got)
+	}
+}
+
+func TestTelegramWebhookNetworks(t *testing.T) {
+	networks := TelegramWebhookNetworks()
+	for _, addr := range []string{"149.154.160.1", "149.154.175.255", "91.108.7.1"} {
+		found := false
+		for _, network := range networks {
+			if network.Contains(netip.MustParseAddr(addr)) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("address %s is not in Telegram networks", addr)
+		}
+	}
+}
+
+func TestWebhookReplyHandlerWritesReplyIntoResponse(t *testing.T) {
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("reply without files was sent through the API: %s", req.URL)
+			return nil, nil
+		},
+	})
+	handler := NewWebhookReplyHandler(bot, "", func(ctx context.Context, update Update) (Chattable, error) {
+		if update.Message == nil {
+			return nil, nil
+		}
+		return NewMessage(update.Message.Chat.ID, "echo: "+update.Message.Text), nil
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1,"message":{"message_id":1,"date":1,"chat":{"id":42,"type":"private"},"text":"hi"}}`))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", recorder.Code)
+	}
+	values, err := url.ParseQuery(recorder.Body.String())
+	if err != nil {
+		t.Fatalf("parse response body: %v", err)
+	}
+	if values.Get("method") != "sendMessage" || values.Get("chat_id") != "42" || values.Get("text") != "echo: hi" {
+		t.Fatalf("unexpected reply: %v", values)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":2,"poll":{"id":"1"}}`))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Fatalf("unexpected empty reply: status %d, body %q", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestWebhookReplyHandlerSendsUploadsThroughAPI(t *testing.T) {
+	var methods []string
+	bot := newFakeBot(fakeHTTPClient{
+		do: func(req *http.Request) (*http.Response, error) {
+			methods = append(methods, req.URL.Path)
+			return okAPIResponse(), nil
+		},
+	})
+	handler := NewWebhookReplyHandler(bot, "", func(ctx context.Context, update Update) (Chattable, error) {
+		return NewPhoto(42, FileBytes{Name: "photo.jpg", Bytes: []byte("image")}), nil
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Fatalf("unexpected response: status %d, body %q", recorder.Code, recorder.Body.String())
+	}
+	if len(methods) != 1 || !strings.HasSuffix(methods[0], "/sendPhoto") {
+		t.Fatalf("upload was not sent through the API: %v", methods)
+	}
+
+	handler.Bot = nil
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":2}`)))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected server error without bot, got %d", recorder.Code)
+	}
+}
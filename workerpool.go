@@ -0,0 +1,132 @@
+package tgbotapi
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// WorkerPoolConfig configures a WorkerPool.
+type WorkerPoolConfig struct {
+	// Workers is the number of updates handled concurrently.
+	// Defaults to runtime.GOMAXPROCS(0).
+	Workers int
+	// QueueSize is the number of updates queued for every worker before
+	// reading from the updates channel blocks. Defaults to 16.
+	QueueSize int
+	// DrainTimeout limits how long queued updates are handled after the
+	// context passed to Run is done. Handlers still running when it expires
+	// see their context cancelled, and updates still queued are dropped.
+	// Zero waits for all queued updates.
+	DrainTimeout time.Duration
+	// OnError is called with errors returned by the handler.
+	OnError func(ctx context.Context, update Update, err error)
+}
+
+// WorkerPool handles updates concurrently while keeping updates from the
+// same chat, or from the same user when an update has no chat, in order.
+type WorkerPool struct {
+	handler UpdateHandlerFunc
+	config  WorkerPoolConfig
+}
+
+// NewWorkerPool creates a WorkerPool passing updates to handler.
+func NewWorkerPool(handler UpdateHandlerFunc, config WorkerPoolConfig) *WorkerPool {
+	if config.Workers <= 0 {
+		config.Workers = runtime.GOMAXPROCS(0)
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 16
+	}
+
+	return &WorkerPool{
+		handler: handler,
+		config:  config,
+	}
+}
+
+// Run handles updates from the channel until the channel is closed or ctx is
+// done. Queued updates are drained before Run returns.
+//
+// Run returns ctx.Err() if it stopped because ctx is done, nil otherwise.
+func (p *WorkerPool) Run(ctx context.Context, updates UpdatesChannel) error {
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+
+	queues := make([]chan Update, p.config.Workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan Update, p.config.QueueSize)
+		wg.Add(1)
+		go func(queue <-chan Update) {
+			defer wg.Done()
+			for update := range queue {
+				if handlerCtx.Err() != nil {
+					continue
+				}
+				p.handle(handlerCtx, update)
+			}
+		}(queues[i])
+	}
+
+	err := p.distribute(ctx, updates, queues)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	if err != nil && p.config.DrainTimeout > 0 {
+		timer := time.AfterFunc(p.config.DrainTimeout, cancelHandlers)
+		defer timer.Stop()
+	}
+
+	wg.Wait()
+
+	return err
+}
+
+func (p *WorkerPool) distribute(ctx context.Context, updates UpdatesChannel, queues []chan Update) error {
+	next := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+
+			idx, ok := updateOrderingKey(update)
+			if ok {
+				idx %= uint64(len(queues))
+			} else {
+				idx = uint64(next)
+				next = (next + 1) % len(queues)
+			}
+
+			// Workers keep consuming their queues, so this never drops an
+			// update that was already received from the channel.
+			queues[idx] <- update
+		}
+	}
+}
+
+func (p *WorkerPool) handle(ctx context.Context, update Update) {
+	if err := p.handler(ctx, update); err != nil && p.config.OnError != nil {
+		p.config.OnError(ctx, update, err)
+	}
+}
+
+// updateOrderingKey returns the chat ID of the update, or the user ID for
+// updates without a chat. Private chats share IDs with their users, so
+// updates from a user and their private chat are kept in the same order.
+func updateOrderingKey(update Update) (uint64, bool) {
+	if chat := update.FromChat(); chat != nil {
+		return uint64(chat.ID), true
+	}
+	if user := update.SentFrom(); user != nil {
+		return uint64(user.ID), true
+	}
+	return 0, false
+}
@@ -0,0 +1,184 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func chatUpdate(updateID int, chatID int64) Update {
+	return Update{
+		UpdateID: updateID,
+		Message:  &Message{Chat: Chat{ID: chatID}},
+	}
+}
+
+func TestWorkerPoolKeepsPerChatOrder(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[int64][]int{}
+
+	pool := NewWorkerPool(func(ctx context.Context, update Update) error {
+		time.Sleep(time.Duration(update.UpdateID%3) * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := update.FromChat().ID
+		seen[chatID] = append(seen[chatID], update.UpdateID)
+		return nil
+	}, WorkerPoolConfig{Workers: 4, QueueSize: 2})
+
+	ch := make(chan Update)
+	go func() {
+		for i := 0; i < 60; i++ {
+			ch <- chatUpdate(i, int64(i%5)-2)
+		}
+		close(ch)
+	}()
+
+	if err := pool.Run(context.Background(), ch); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	total := 0
+	for chatID, ids := range seen {
+		total += len(ids)
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Fatalf("chat %d handled out of order: %v", chatID, ids)
+			}
+		}
+	}
+	if total != 60 {
+		t.Fatalf("expected 60 updates, got %d", total)
+	}
+}
+
+func TestWorkerPoolHandlesChatsConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	var running atomic.Int32
+
+	pool := NewWorkerPool(func(ctx context.Context, update Update) error {
+		if running.Add(1) == 2 {
+			close(release)
+		}
+		select {
+		case <-release:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("updates were handled sequentially")
+		}
+	}, WorkerPoolConfig{
+		Workers: 2,
+		OnError: func(ctx context.Context, update Update, err error) {
+			t.Errorf("update %d: %v", update.UpdateID, err)
+		},
+	})
+
+	ch := make(chan Update, 2)
+	ch <- chatUpdate(1, 1)
+	ch <- chatUpdate(2, 2)
+	close(ch)
+
+	if err := pool.Run(context.Background(), ch); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+}
+
+func TestWorkerPoolDrainsOnShutdown(t *testing.T) {
+	var handled atomic.Int32
+	started := make(chan struct{}, 10)
+
+	pool := NewWorkerPool(func(ctx context.Context, update Update) error {
+		started <- struct{}{}
+		time.Sleep(5 * time.Millisecond)
+		if ctx.Err() != nil {
+			t.Errorf("handler context cancelled during drain")
+		}
+		handled.Add(1)
+		return nil
+	}, WorkerPoolConfig{Workers: 1, QueueSize: 10})
+
+	ch := make(chan Update, 5)
+	for i := 0; i < 5; i++ {
+		ch <- chatUpdate(i, 1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	if err := pool.Run(ctx, ch); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled run, got %v", err)
+	}
+	if handled.Load() == 0 {
+		t.Fatalf("queued updates were not drained")
+	}
+	if got := int(handled.Load()) + len(ch); got != 5 {
+		t.Fatalf("updates were lost: handled %d, left %d", handled.Load(), len(ch))
+	}
+}
+
+func TestWorkerPoolDrainTimeoutCancelsHandlers(t *testing.T) {
+	started := make(chan struct{})
+
+	pool := NewWorkerPool(func(ctx context.Context, update Update) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, WorkerPoolConfig{Workers: 1, DrainTimeout: 10 * time.Millisecond})
+
+	ch := make(chan Update, 1)
+	ch <- chatUpdate(1, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- pool.Run(ctx, ch) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled run, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("drain timeout did not cancel handler")
+	}
+}
+
+func TestWorkerPoolDrainTimeoutDropsQueuedUpdates(t *testing.T) {
+	var handled atomic.Int32
+	started := make(chan struct{}, 3)
+
+	pool := NewWorkerPool(func(ctx context.Context, update Update) error {
+		handled.Add(1)
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}, WorkerPoolConfig{Workers: 1, QueueSize: 10, DrainTimeout: 10 * time.Millisecond})
+
+	ch := make(chan Update, 3)
+	for i := 0; i < 3; i++ {
+		ch <- chatUpdate(i, 1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	if err := pool.Run(ctx, ch); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled run, got %v", err)
+	}
+	if got := handled.Load(); got != 1 {
+		t.Fatalf("expected queued updates to be dropped, handled %d", got)
+	}
+}